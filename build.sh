@@ -1,5 +1,5 @@
 #!/bin/sh
 # version=`date -u +"v%Y.%m%d"`
 flags="-s -w -extldflags \"-static -fpic\" "
-go build -ldflags "$flags" -o rss2telegram cmd/main.go
+go build -ldflags "$flags" -o rss2telegram ./cmd
 #&& upx -9 ./rss2telegram
\ No newline at end of file
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/rss"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+)
+
+// adminNotifier 将配置重新加载的结果发送到 telegram.admin_chat
+type adminNotifier struct {
+	mu       sync.Mutex
+	bot      rss.TelegramBot
+	config   *config.Config // 当前生效的配置
+	redactor *redactWriter  // 与日志相同的密钥隐藏规则
+}
+
+func newAdminNotifier(bot rss.TelegramBot, cfg *config.Config, redactor *redactWriter) *adminNotifier {
+	return &adminNotifier{bot: bot, config: cfg, redactor: redactor}
+}
+
+// reloaded 发送重新加载成功的通知，附带源的变化摘要
+func (n *adminNotifier) reloaded(newCfg *config.Config) {
+	n.mu.Lock()
+	diff := config.DiffFeeds(n.config, newCfg)
+	n.config = newCfg
+	n.mu.Unlock()
+
+	n.send(newCfg.Telegram.AdminChat, fmt.Sprintf("✅ 配置已重新加载，共 %d 个源\n%s", len(newCfg.Feeds), diff))
+}
+
+// reloadFailed 发送重新加载失败的通知
+func (n *adminNotifier) reloadFailed(err error) {
+	n.mu.Lock()
+	chat := n.config.Telegram.AdminChat
+	n.mu.Unlock()
+
+	n.send(chat, fmt.Sprintf("❌ 配置重新加载失败，继续使用当前配置\n%v", err))
+}
+
+func (n *adminNotifier) send(chat, message string) {
+	if chat == "" {
+		return
+	}
+	// 加载错误中可能包含 Bot Token 等密钥
+	message = string(n.redactor.redact([]byte(message)))
+	// 错误信息和源名称中可能包含 Markdown 字符，以纯文本发送
+	if _, err := n.bot.Send(chat, message, &telegram.SendOptions{ParseMode: telegram.ParseModeNone}); err != nil {
+		log.Printf("Error notifying admin chat %s: %v", chat, err)
+	}
+}
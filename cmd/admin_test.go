@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newRedactWriter(&out)
+	w.add("")
+	w.add("x")
+	w.add("123:abc")
+	w.add("123:abc")
+
+	n, err := w.Write([]byte("GET /bot123:abc/getMe x"))
+	assert.NoError(t, err)
+	assert.Equal(t, len("GET /bot123:abc/getMe x"), n)
+	assert.Equal(t, "GET /bot[REDACTED]/getMe [REDACTED]", out.String())
+}
+
+func TestAdminNotifierRedactsReloadError(t *testing.T) {
+	var out bytes.Buffer
+	redactor := newRedactWriter(&bytes.Buffer{})
+	redactor.add("123:abc")
+	cfg := &config.Config{Telegram: config.TelegramConfig{AdminChat: "@admin"}}
+	admin := newAdminNotifier(telegram.NewRecorder(&out), cfg, redactor)
+
+	admin.reloadFailed(errors.New(`Get "https://api.telegram.org/bot123:abc/getMe": timeout`))
+
+	var record telegram.Record
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "@admin", record.Channel)
+	assert.Contains(t, record.Text, "bot[REDACTED]/getMe")
+	assert.NotContains(t, record.Text, "123:abc")
+}
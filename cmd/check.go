@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/rss"
+)
+
+// runCheckConfig 检查配置文件，配置无效时以非零退出码退出，适合在 CI 中使用
+// rss2telegram check-config [参数] [配置文件或目录]
+func runCheckConfig(args []string) int {
+	fs := flag.NewFlagSet("check-config", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "path to configuration file or directory")
+	strict := fs.Bool("strict", false, "treat warnings such as unknown fields as errors")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s check-config [flags] [config file or directory]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+	path := *configPath
+	if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	cfg, warnings, err := config.Check(path, rss.ValidateTemplate)
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	if *strict && len(warnings) > 0 {
+		fmt.Fprintf(os.Stderr, "error: warnings are not allowed in strict mode\n")
+		return 1
+	}
+
+	fmt.Printf("%s: OK, %d feeds\n", path, len(cfg.Feeds))
+	return 0
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 内置时区数据，运行环境缺少时区数据库时也能加载 IANA 时区
 
 	"github.com/Hootrix/rss2telegram/internal/config"
 	"github.com/Hootrix/rss2telegram/internal/rss"
@@ -16,14 +17,41 @@ import (
 	"github.com/Hootrix/rss2telegram/internal/telegram"
 )
 
+// 子命令，返回进程退出码
+var commands = map[string]func(args []string) int{
+	"preview":      runPreview,
+	"check-config": runCheckConfig,
+	"import-opml":  runImportOPML,
+	"export-opml":  runExportOPML,
+	"schema":       runSchema,
+}
+
 func main() {
 	// 设置日志格式
 	log.SetFlags(log.Ldate | log.Ltime | log.Lmicroseconds | log.Lshortfile)
+	// 日志中隐藏 Bot Token
+	logWriter := newRedactWriter(os.Stderr)
+	log.SetOutput(logWriter)
+
+	// 执行子命令
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
 
 	// 解析命令行参数 读取配置文件
-	configPath := flag.String("config", "config/config.yaml", "path to configuration file")
+	configPath := flag.String("config", "config/config.yaml", "path to configuration file or directory")
+	dryRun := flag.Bool("dry-run", false, "record messages as JSON Lines instead of sending them, keeping seen items in memory only")
+	dryRunOutput := flag.String("dry-run-output", "-", "file to append dry-run records to, - for stdout")
+	once := flag.Bool("once", false, "check all feeds once and exit, with a non-zero exit code when any feed fails")
+	checkOnStart := flag.Bool("check-on-start", false, "check all feeds immediately on start instead of waiting for the first interval")
 	flag.Parse()
 
+	// 退出码，最后执行
+	exitCode := 0
+	defer func() { os.Exit(exitCode) }()
+
 	// 创建上下文，用于优雅退出
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -38,17 +66,26 @@ func main() {
 	}()
 
 	// 使用文件监听 读取配置文件。
-	// 内容变化后自动应用最新配置
-	cfgManager, err := config.NewManager(*configPath)
+	// 内容变化后自动应用最新配置；单次运行时只读取一次
+	var cfg *config.Config
+	var cfgManager *config.Manager
+	var err error
+	if *once {
+		cfg, err = config.LoadFile(*configPath, rss.ValidateTemplate)
+	} else {
+		cfgManager, err = config.NewManager(*configPath, rss.ValidateTemplate)
+	}
 	if err != nil {
 		log.Fatalf("Error loading config: %v", err)
 	}
-	defer cfgManager.Close()
-
-	cfg := cfgManager.Get()
+	if cfgManager != nil {
+		defer cfgManager.Close()
+		cfg = cfgManager.Get()
+	}
+	logWriter.add(cfg.Telegram.BotToken)
 
 	// 初始化存储
-	dataDir := filepath.Join(filepath.Dir(*configPath), "rss2telegram-data")
+	dataDir := filepath.Join(config.BaseDir(*configPath), "rss2telegram-data")
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		log.Fatalf("Error creating data directory: %v", err)
 	}
@@ -58,19 +95,63 @@ func main() {
 		log.Fatalf("Error initializing storage: %v", err)
 	}
 
-	// 创建 Telegram 机器人
-	bot, err := telegram.NewBot(cfg.Telegram.BotToken)
-	if err != nil {
-		log.Fatalf("Error creating Telegram bot: %v", err)
+	// 创建 Telegram 机器人，dry-run 模式下使用记录器代替
+	var bot rss.TelegramBot
+	if *dryRun {
+		out := os.Stdout
+		if *dryRunOutput != "-" {
+			out, err = os.OpenFile(*dryRunOutput, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+			if err != nil {
+				log.Fatalf("Error opening dry-run output: %v", err)
+			}
+			defer out.Close()
+		}
+		bot = telegram.NewRecorder(out)
+		// 推送状态只保存在内存中
+		store.SetReadOnly(true)
+		log.Printf("Dry-run mode: messages are recorded to %s and not sent", *dryRunOutput)
+	} else {
+		bot, err = telegram.NewBot(cfg.Telegram.BotToken)
+		if err != nil {
+			log.Fatalf("Error creating Telegram bot: %v", err)
+		}
 	}
 
 	// 创建 RSS 处理器
 	rssHandler := rss.NewRssHandler(cfg, bot, store)
+	rssHandler.SetDryRun(*dryRun)
 
-	// 注册配置变更回调
+	// 单次运行：检查所有源后退出，推送状态在发送成功后已保存
+	if *once {
+		if err := rssHandler.ProcessFeeds(); err != nil {
+			log.Printf("Error processing feeds: %v", err)
+			exitCode = 1
+			return
+		}
+		log.Printf("All feeds processed")
+		return
+	}
+
+	// 注册配置变更回调，重新加载的结果发送到管理员聊天
+	admin := newAdminNotifier(bot, cfg, logWriter)
 	cfgManager.OnConfigChange(func(newCfg *config.Config) {
+		logWriter.add(newCfg.Telegram.BotToken)
 		rssHandler.UpdateConfig(newCfg)
+		admin.reloaded(newCfg)
 	})
+	cfgManager.OnReloadError(admin.reloadFailed)
+
+	// 收到 SIGHUP 时重新加载配置
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	go func() {
+		for range hupChan {
+			log.Printf("Received SIGHUP, reloading config")
+			if err := cfgManager.Load(); err != nil {
+				log.Printf("Config Reload Error: %v", err)
+			}
+		}
+	}()
 
 	// 定时检查 RSS 更新
 	ticker := time.NewTicker(time.Duration(cfg.Telegram.CheckInterval) * time.Second)
@@ -81,6 +162,13 @@ func main() {
 	// 记录启动时间
 	startTime := time.Now()
 
+	// 启动后立即检查一次，不等待第一个检查间隔
+	if *checkOnStart {
+		if err := rssHandler.ProcessFeeds(); err != nil {
+			log.Printf("Error processing feeds: %v", err)
+		}
+	}
+
 	// 主循环
 	for {
 		select {
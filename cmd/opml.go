@@ -0,0 +1,181 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/opml"
+	"github.com/Hootrix/rss2telegram/internal/rss"
+	"gopkg.in/yaml.v3"
+)
+
+// folderChannels 文件夹到频道的映射，命令行中使用 -folder 文件夹=@频道 指定，可以重复
+type folderChannels map[string]string
+
+func (f folderChannels) String() string {
+	var pairs []string
+	for folder, channel := range f {
+		pairs = append(pairs, folder+"="+channel)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f folderChannels) Set(value string) error {
+	folder, channel, ok := strings.Cut(value, "=")
+	if !ok || channel == "" {
+		return fmt.Errorf("expected folder=@channel, got %q", value)
+	}
+	f[folder] = channel
+	return nil
+}
+
+// importedFeed 导入生成的 feed 配置，只包含 OPML 中的信息
+type importedFeed struct {
+	Name     string   `yaml:"name"`
+	URL      string   `yaml:"url"`
+	Channels []string `yaml:"channels,flow"`
+}
+
+// runImportOPML 将 OPML 中的订阅转换为 feeds 配置，输出可作为 include 文件使用
+// rss2telegram import-opml <OPML 文件> [参数]
+func runImportOPML(args []string) int {
+	fs := flag.NewFlagSet("import-opml", flag.ExitOnError)
+	channel := fs.String("channel", "", "channel for feeds whose folder has no -folder mapping")
+	folders := folderChannels{}
+	fs.Var(folders, "folder", "map an OPML folder to a channel, e.g. -folder Tech=@tech_news (repeatable)")
+	output := fs.String("o", "-", "file to write the feeds config to, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s import-opml <file> [flags]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	positional := parseInterspersed(fs, args)
+	if len(positional) != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	file, err := os.Open(positional[0])
+	if err != nil {
+		log.Printf("Error opening OPML file: %v", err)
+		return 1
+	}
+	defer file.Close()
+	doc, err := opml.Parse(file)
+	if err != nil {
+		log.Printf("%v", err)
+		return 1
+	}
+
+	subs, err := doc.Subscriptions(folders, *channel)
+	if err != nil {
+		log.Printf("%v, use -channel or -folder", err)
+		return 1
+	}
+	feeds := make([]importedFeed, 0, len(subs))
+	for _, sub := range subs {
+		feeds = append(feeds, importedFeed{Name: sub.Name, URL: sub.URL, Channels: sub.Channels})
+	}
+
+	err = writeOutput(*output, func(w io.Writer) error {
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string][]importedFeed{"feeds": feeds}); err != nil {
+			return err
+		}
+		return encoder.Close()
+	})
+	if err != nil {
+		log.Printf("Error writing feeds config: %v", err)
+		return 1
+	}
+	log.Printf("Imported %d feeds", len(feeds))
+	return 0
+}
+
+// runExportOPML 将配置中的 feed 按频道分组导出为 OPML，订阅到多个频道的 feed 在每个频道中各出现一次
+// rss2telegram export-opml [参数]
+func runExportOPML(args []string) int {
+	fs := flag.NewFlagSet("export-opml", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "path to configuration file or directory")
+	output := fs.String("o", "-", "file to write the OPML to, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s export-opml [flags]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	cfg, err := config.LoadFile(*configPath, rss.ValidateTemplate)
+	if err != nil {
+		log.Printf("Error loading config: %v", err)
+		return 1
+	}
+
+	// 按频道首次出现的顺序分组
+	var groups []opml.Outline
+	index := make(map[string]int)
+	for _, feed := range cfg.Feeds {
+		for _, channel := range feed.Channels {
+			i, ok := index[channel]
+			if !ok {
+				i = len(groups)
+				index[channel] = i
+				groups = append(groups, opml.Outline{Text: channel, Title: channel})
+			}
+			groups[i].Outlines = append(groups[i].Outlines, opml.Outline{
+				Text:   feed.Name,
+				Title:  feed.Name,
+				Type:   "rss",
+				XMLURL: feed.URL,
+			})
+		}
+	}
+
+	doc := &opml.OPML{
+		Head: opml.Head{Title: "rss2telegram", DateCreated: time.Now().Format(time.RFC1123Z)},
+		Body: opml.Body{Outlines: groups},
+	}
+	if err := writeOutput(*output, func(w io.Writer) error { return opml.Write(w, doc) }); err != nil {
+		log.Printf("Error writing OPML: %v", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed 解析参数，允许标志出现在位置参数之后，例如 import-opml feeds.opml -channel @news
+// 返回位置参数
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// writeOutput 写入文件，path 为 - 时写入标准输出
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/rss"
+)
+
+// runPreview 预览模板渲染结果，不读写存储、不发送消息
+// rss2telegram preview [参数] <feed 名称|URL|本地文件>
+func runPreview(args []string) int {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	configPath := fs.String("config", "config/config.yaml", "path to configuration file or directory")
+	template := fs.String("template", "", "template to render instead of the feed template, supports @name")
+	templateFile := fs.String("template-file", "", "read the template to render from a file")
+	engine := fs.String("engine", "", "template engine: brace or go (default: the feed setting)")
+	limit := fs.Int("n", 3, "number of latest items to render, 0 renders all items")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s preview [flags] <feed name|url|file>\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	target := fs.Arg(0)
+
+	// 优先按名称查找配置中的 feed，找不到时将参数作为 URL 或本地文件
+	cfg, err := config.LoadFile(*configPath, rss.ValidateTemplate)
+	var feedConfig *config.FeedConfig
+	if err == nil {
+		for i := range cfg.Feeds {
+			if cfg.Feeds[i].Name == target {
+				feedConfig = &cfg.Feeds[i]
+				break
+			}
+		}
+	}
+	if feedConfig == nil {
+		if !isFeedSource(target) {
+			if err != nil {
+				log.Printf("Error loading config: %v", err)
+			}
+			log.Printf("Feed %q not found in config and is not a URL or file", target)
+			return 1
+		}
+		if err != nil {
+			log.Printf("Config not loaded, using defaults: %v", err)
+			cfg = &config.Config{}
+		}
+	}
+
+	// 命令行指定的模板覆盖 feed 的模板，支持共享模板和模板变量
+	if *templateFile != "" {
+		data, err := os.ReadFile(*templateFile)
+		if err != nil {
+			log.Printf("Error reading template file: %v", err)
+			return 1
+		}
+		*template = string(data)
+	}
+	feedConfig, err = previewFeed(cfg, feedConfig, target, *template, *engine, config.BaseDir(*configPath))
+	if err != nil {
+		log.Printf("Error resolving template: %v", err)
+		return 1
+	}
+	if err := rss.ValidateTemplate(feedConfig.TemplateEngine, feedConfig.Template); err != nil {
+		log.Printf("Invalid template: %v", err)
+		return 1
+	}
+
+	handler := rss.NewRssHandler(cfg, nil, nil)
+	messages, err := handler.Preview(*feedConfig, *limit)
+	if err != nil {
+		log.Printf("Error previewing feed: %v", err)
+		return 1
+	}
+
+	for i, msg := range messages {
+		fmt.Printf("===== %d/%d %s =====\n%s\n", i+1, len(messages), msg.Title, msg.Message)
+		for _, button := range msg.Buttons {
+			fmt.Printf("[%s](%s)\n", button.Text, button.URL)
+		}
+		fmt.Println()
+	}
+	return 0
+}
+
+// previewFeed 生成预览使用的 feed 配置
+// feed 为 nil 时 target 为 URL 或本地文件，与配置中的源一样应用 defaults（解析模式、模板引擎、模板等）；
+// template 和 engine 为命令行指定的模板和模板引擎，只指定模板引擎时使用该引擎的默认模板
+func previewFeed(cfg *config.Config, feed *config.FeedConfig, target, template, engine, baseDir string) (*config.FeedConfig, error) {
+	if feed == nil {
+		defaulted := config.Config{Templates: cfg.Templates, Feeds: []config.FeedConfig{
+			cfg.FeedWithDefaults(config.FeedConfig{Name: target, URL: target}),
+		}}
+		if _, err := defaulted.ResolveTemplates(baseDir); err != nil {
+			return nil, err
+		}
+		feed = &defaulted.Feeds[0]
+	}
+	result := *feed
+
+	if engine != "" {
+		// 源的模板使用原模板引擎的语法
+		if template == "" && !config.SameTemplateEngine(engine, result.TemplateEngine) {
+			result.Template, result.TemplateFile = config.DefaultTemplateFor(engine, result.ParseMode), ""
+		}
+		result.TemplateEngine = engine
+	}
+	if template == "" {
+		return &result, nil
+	}
+
+	result.Template, result.TemplateFile = template, ""
+	resolved := config.Config{Templates: cfg.Templates, Feeds: []config.FeedConfig{result}}
+	if _, err := resolved.ResolveTemplates(baseDir); err != nil {
+		return nil, err
+	}
+	return &resolved.Feeds[0], nil
+}
+
+// isFeedSource 判断参数是否为 http(s) 地址或存在的本地文件
+func isFeedSource(target string) bool {
+	if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+		return true
+	}
+	info, err := os.Stat(target)
+	return err == nil && !info.IsDir()
+}
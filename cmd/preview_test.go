@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewFeed(t *testing.T) {
+	cfg := &config.Config{
+		Defaults:  config.FeedDefaults{ParseMode: config.ParseModeHTML, Template: "@short"},
+		Templates: map[string]config.SharedTemplate{"short": {Template: "<b>{title}</b>"}},
+	}
+	feed := &config.FeedConfig{Name: "a", URL: "https://example.com/a.xml", Template: "{title} {link}"}
+
+	tests := []struct {
+		name          string
+		feed          *config.FeedConfig
+		template      string
+		engine        string
+		wantTemplate  string
+		wantEngine    string
+		wantParseMode string
+	}{
+		{
+			name:          "URL 应用 defaults",
+			wantTemplate:  "<b>{title}</b>",
+			wantParseMode: config.ParseModeHTML,
+		},
+		{
+			name:          "URL 指定模板",
+			template:      "{title}",
+			wantTemplate:  "{title}",
+			wantParseMode: config.ParseModeHTML,
+		},
+		{
+			name:          "URL 只指定模板引擎",
+			engine:        config.TemplateEngineGo,
+			wantTemplate:  config.DefaultTemplateFor(config.TemplateEngineGo, config.ParseModeHTML),
+			wantEngine:    config.TemplateEngineGo,
+			wantParseMode: config.ParseModeHTML,
+		},
+		{
+			name:         "源的模板",
+			feed:         feed,
+			wantTemplate: "{title} {link}",
+		},
+		{
+			name:         "相同的模板引擎保留源的模板",
+			feed:         feed,
+			engine:       config.TemplateEngineBrace,
+			wantTemplate: "{title} {link}",
+			wantEngine:   config.TemplateEngineBrace,
+		},
+		{
+			name:         "只指定模板引擎",
+			feed:         feed,
+			engine:       config.TemplateEngineGo,
+			wantTemplate: config.DefaultGoTemplate,
+			wantEngine:   config.TemplateEngineGo,
+		},
+		{
+			name:         "同时指定模板和模板引擎",
+			feed:         feed,
+			template:     "{{.Title}}",
+			engine:       config.TemplateEngineGo,
+			wantTemplate: "{{.Title}}",
+			wantEngine:   config.TemplateEngineGo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := previewFeed(cfg, tt.feed, "https://example.com/b.xml", tt.template, tt.engine, t.TempDir())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantTemplate, result.Template)
+			assert.Equal(t, tt.wantEngine, result.TemplateEngine)
+			assert.Equal(t, tt.wantParseMode, result.ParseMode)
+		})
+	}
+	// 源的配置不被修改
+	assert.Equal(t, "{title} {link}", feed.Template)
+}
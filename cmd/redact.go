@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// redactWriter 在写入日志前将密钥替换为 [REDACTED]
+// 网络错误中的请求地址等内容可能包含 Bot Token
+type redactWriter struct {
+	mu      sync.RWMutex
+	out     io.Writer
+	secrets [][]byte
+}
+
+func newRedactWriter(out io.Writer) *redactWriter {
+	return &redactWriter{out: out}
+}
+
+// add 添加需要隐藏的密钥，忽略空值和重复值
+func (w *redactWriter) add(secret string) {
+	if secret == "" {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, s := range w.secrets {
+		if string(s) == secret {
+			return
+		}
+	}
+	w.secrets = append(w.secrets, []byte(secret))
+}
+
+// redact 将内容中的密钥替换为 [REDACTED]
+func (w *redactWriter) redact(p []byte) []byte {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	for _, secret := range w.secrets {
+		p = bytes.ReplaceAll(p, secret, []byte("[REDACTED]"))
+	}
+	return p
+}
+
+func (w *redactWriter) Write(p []byte) (int, error) {
+	if _, err := w.out.Write(w.redact(p)); err != nil {
+		return 0, err
+	}
+	// 返回原始长度，log 包按此判断写入是否完整
+	return len(p), nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+)
+
+// runSchema 输出配置文件的 JSON Schema，供编辑器检查和补全配置
+// rss2telegram schema [参数]
+func runSchema(args []string) int {
+	fs := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := fs.String("o", "-", "file to write the JSON Schema to, - for stdout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s schema [flags]\n", filepath.Base(os.Args[0]))
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	err := writeOutput(*output, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(config.Schema())
+	})
+	if err != nil {
+		log.Printf("Error writing schema: %v", err)
+		return 1
+	}
+	return 0
+}
@@ -3,6 +3,7 @@ module github.com/Hootrix/rss2telegram
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/JohannesKaufmann/html-to-markdown v1.6.0
 	github.com/bits-and-blooms/bloom/v3 v3.5.0
 	github.com/fsnotify/fsnotify v1.8.0
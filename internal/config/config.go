@@ -3,21 +3,69 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Telegram TelegramConfig `yaml:"telegram"`
-	Feeds    []FeedConfig   `yaml:"feeds"`
+	// 模板中时间字段使用的默认 IANA 时区，为空时保持 feed 中的原始时区
+	Timezone string `yaml:"timezone"`
+	// 共享模板，feed 中通过 template: "@name" 引用
+	Templates map[string]SharedTemplate `yaml:"templates"`
+	// 所有 feed 共用的默认设置
+	Defaults FeedDefaults `yaml:"defaults"`
+	// 引入其他配置文件，支持 glob，例如 feeds/*.yaml
+	Include []string     `yaml:"include"`
+	Feeds   []FeedConfig `yaml:"feeds"`
+
+	// telegram、timezone 和共享模板在配置文件中的位置，加载时设置
+	Positions Positions `yaml:"-"`
+}
+
+// Positions 顶层配置项在配置文件中的位置，用于错误信息
+type Positions struct {
+	Telegram  Position
+	Timezone  Position
+	Templates map[string]Position // 共享模板名称 -> 位置
+}
+
+// Position 配置项在配置文件中的位置
+type Position struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// String 返回位置描述，例如 config.yaml:2:1，没有行号（TOML 配置）时只返回文件名
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Column)
+}
+
+// positionError 在错误信息前添加配置项的位置，位置未知时（例如配置不是从文件加载）不添加
+func positionError(pos Position, err error) error {
+	if pos.File == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", pos, err)
 }
 
 type TelegramConfig struct {
-	BotToken      string `yaml:"bot_token"`
+	BotToken string `yaml:"bot_token"`
+	// 从文件读取 Bot Token（如 Docker/Kubernetes 挂载的 secret），与 bot_token 二选一
+	BotTokenFile  string `yaml:"bot_token_file"`
 	CheckInterval int    `yaml:"check_interval"`
+	// 接收配置重新加载结果的聊天（@username 或数字 ID），为空时不通知
+	AdminChat string `yaml:"admin_chat"`
 }
 
 type FeedConfig struct {
@@ -27,16 +75,172 @@ type FeedConfig struct {
 	FirstPush                      bool     `yaml:"first_push"`
 	Channels                       []string `yaml:"channels"`
 	Template                       string   `yaml:"template"`
+	// 模板文件路径，相对路径基于配置文件所在目录，与 template 二选一
+	TemplateFile string `yaml:"template_file"`
+	// 模板变量，覆盖共享模板中的同名变量
+	Vars map[string]string `yaml:"vars"`
+	// 模板引擎：brace(默认，{field|op:param} 语法) / go(text/template 语法)
+	TemplateEngine string `yaml:"template_engine"`
+	// 模板中时间字段使用的 IANA 时区，覆盖全局设置
+	Timezone string `yaml:"timezone"`
+	// 消息解析模式：markdown(默认) / markdownv2 / html / none
+	ParseMode string `yaml:"parse_mode"`
+	// 模板中 {tags} 字段的生成规则
+	Tags TagsConfig `yaml:"tags"`
+
+	// feed 级别的发送选项，对所有频道生效
+	DeliveryOptions `yaml:",inline"`
+	// 频道级别的发送选项，覆盖 feed 级别的同名设置
+	ChannelOptions map[string]DeliveryOptions `yaml:"channel_options"`
+	// 消息下方的内联按钮，url 支持模板字段
+	Buttons []ButtonConfig `yaml:"buttons"`
+
+	// 已推送文章内容变化后的处理方式
+	OnUpdate string `yaml:"on_update"`
+	// 已推送文章从feed中移除后的处理方式
+	OnRemoved string `yaml:"on_removed"`
+	// 文章连续多少次抓取不在feed中时视为已移除，默认 3
+	RemovedAfter int `yaml:"removed_after"`
+
+	// 推送方式：instant(默认，逐篇推送) / digest(定时汇总推送)
+	Delivery string       `yaml:"delivery"`
+	Digest   DigestConfig `yaml:"digest"`
+
+	// 定义该 feed 的配置文件和位置，加载时设置
+	Source FeedSource `yaml:"-"`
+}
+
+// FeedSource feed 在配置文件中的位置
+type FeedSource struct {
+	File   string
+	Index  int // 在该文件 feeds 中的序号，从 0 开始
+	Line   int
+	Column int
+}
+
+// String 返回位置描述，例如 config.yaml:12:5: feeds[2]，没有行号（TOML 配置）时省略行列
+func (s FeedSource) String() string {
+	if s.Line == 0 {
+		return fmt.Sprintf("%s: feeds[%d]", s.File, s.Index)
+	}
+	return fmt.Sprintf("%s:%d:%d: feeds[%d]", s.File, s.Line, s.Column, s.Index)
+}
+
+// TagsConfig {tags} 字段配置
+type TagsConfig struct {
+	// 标题或分类中包含关键词（不区分大小写）时添加对应标签，关键词 -> 标签
+	Keywords map[string]string `yaml:"keywords"`
+	// 最多保留的标签数量，0 表示不限制
+	Limit int `yaml:"limit"`
+}
+
+// 模板引擎
+const (
+	TemplateEngineBrace = "brace"
+	TemplateEngineGo    = "go"
+)
+
+// 推送方式
+const (
+	DeliveryInstant = "instant"
+	DeliveryDigest  = "digest"
+)
+
+// DigestConfig 摘要推送配置
+type DigestConfig struct {
+	Schedule string `yaml:"schedule"` // 推送计划，如 "every 3h"、"daily 09:00" 或 "weekly mon 09:00"
+	Timezone string `yaml:"timezone"` // daily 和 weekly 计划使用的 IANA 时区，默认本地时区
+	// 摘要消息模板，可用变量：{items} {count} {name} {date}
+	Template string `yaml:"template"`
+	// 摘要中单篇文章的模板，支持与 template 相同的字段和操作符
+	ItemTemplate string `yaml:"item_template"`
+}
+
+// DefaultRemovedAfter 默认连续缺失次数
+const DefaultRemovedAfter = 3
+
+// 文章移除处理方式
+const (
+	OnRemovedNone   = "none"   // 不处理（默认）
+	OnRemovedDelete = "delete" // 删除已发送的消息
+	OnRemovedExpire = "expire" // 编辑已发送的消息，标记为已失效
+)
+
+// 文章更新处理方式
+const (
+	OnUpdateIgnore = "ignore" // 忽略（默认）
+	OnUpdateEdit   = "edit"   // 编辑已发送的消息
+	OnUpdateReply  = "reply"  // 回复已发送的消息，附带更新后的内容
+)
+
+// DeliveryOptions 消息发送选项。未设置(nil)的字段沿用上一级的值
+type DeliveryOptions struct {
+	DisableNotification   *bool `yaml:"disable_notification"`
+	DisableWebPagePreview *bool `yaml:"disable_web_page_preview"`
+	ProtectContent        *bool `yaml:"protect_content"`
+
+	// 免打扰时段
+	QuietHours *QuietHoursConfig `yaml:"quiet_hours"`
+}
+
+// ButtonConfig 内联按钮配置
+type ButtonConfig struct {
+	Text string `yaml:"text"`
+	URL  string `yaml:"url"`
+}
+
+// Merge 使用 override 中已设置的字段覆盖当前选项
+func (o DeliveryOptions) Merge(override DeliveryOptions) DeliveryOptions {
+	if override.DisableNotification != nil {
+		o.DisableNotification = override.DisableNotification
+	}
+	if override.DisableWebPagePreview != nil {
+		o.DisableWebPagePreview = override.DisableWebPagePreview
+	}
+	if override.ProtectContent != nil {
+		o.ProtectContent = override.ProtectContent
+	}
+	if override.QuietHours != nil {
+		o.QuietHours = override.QuietHours
+	}
+	return o
 }
 
-// Validate 验证配置的合法性
-func (c *Config) Validate() error {
+// OptionsFor 获取指定频道最终生效的发送选项
+func (f *FeedConfig) OptionsFor(channel string) DeliveryOptions {
+	return f.DeliveryOptions.Merge(f.ChannelOptions[channel])
+}
+
+// TemplateValidator 检查模板是否有效，参数为模板引擎和模板内容
+// 由模板引擎所在的包提供（rss.ValidateTemplate），加载和验证配置时传入
+type TemplateValidator func(engine, template string) error
+
+// validateTemplate 使用 validate 检查模板，validate 为 nil 时不检查模板，空模板使用默认模板，不检查
+func validateTemplate(validate TemplateValidator, engine, template string) error {
+	if validate == nil || template == "" {
+		return nil
+	}
+	return validate(engine, template)
+}
+
+// 频道格式：@username（不超过 32 个字母、数字或下划线，以字母开头）或数字 ID
+var channelRegex = regexp.MustCompile(`^(@[A-Za-z][A-Za-z0-9_]{0,31}|-?\d+)$`)
+
+// Validate 验证配置的合法性，模板使用 validate 检查，为 nil 时不检查模板
+func (c *Config) Validate(validate TemplateValidator) error {
 	// 检查 Telegram 配置
 	if c.Telegram.BotToken == "" {
-		return fmt.Errorf("telegram bot token is required")
+		return positionError(c.Positions.Telegram, fmt.Errorf("telegram bot token is required"))
 	}
 	if c.Telegram.CheckInterval <= 0 {
-		return fmt.Errorf("telegram check interval must be positive")
+		return positionError(c.Positions.Telegram, fmt.Errorf("telegram check interval must be positive"))
+	}
+	if c.Telegram.AdminChat != "" && !channelRegex.MatchString(c.Telegram.AdminChat) {
+		return positionError(c.Positions.Telegram, fmt.Errorf("telegram admin_chat %q: expected @username or numeric chat ID", c.Telegram.AdminChat))
+	}
+
+	if _, err := LoadLocation(c.Timezone); err != nil {
+		return positionError(c.Positions.Timezone, fmt.Errorf("timezone: %w", err))
 	}
 
 	// 检查 Feeds 配置
@@ -44,60 +248,201 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("at least one feed must be configured")
 	}
 
-	// 用于检查名称唯一性
-	names := make(map[string]bool)
+	// 用于检查名称唯一性，记录定义该名称的配置文件
+	names := make(map[string]string)
 	// 用于检查 URL 和名称组合的唯一性
 	urlNamePairs := make(map[string]bool)
 
-	for _, feed := range c.Feeds {
-		// 检查必填字段
-		if feed.Name == "" {
-			return fmt.Errorf("feed name is required")
+	for i, feed := range c.Feeds {
+		if err := validateFeed(feed, names, urlNamePairs, validate); err != nil {
+			return feedError(i, feed, err)
+		}
+	}
+
+	return nil
+}
+
+// validateFeed 验证单个 feed 的配置，names 和 urlNamePairs 用于检查唯一性
+func validateFeed(feed FeedConfig, names map[string]string, urlNamePairs map[string]bool, validate TemplateValidator) error {
+	// 检查必填字段
+	if feed.Name == "" {
+		return fmt.Errorf("feed name is required")
+	}
+	if feed.URL == "" {
+		return fmt.Errorf("feed URL is required")
+	}
+	if u, err := url.Parse(feed.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("feed %s: invalid URL %q, expected an http(s) URL", feed.Name, feed.URL)
+	}
+	if len(feed.Channels) == 0 {
+		return fmt.Errorf("feed %s must have at least one channel", feed.Name)
+	}
+	for _, channel := range feed.Channels {
+		if !channelRegex.MatchString(channel) {
+			return fmt.Errorf("feed %s: invalid channel %q, expected @username or numeric chat ID", feed.Name, channel)
+		}
+	}
+
+	// 检查名称唯一性
+	if source, ok := names[feed.Name]; ok {
+		if source != feed.Source.File {
+			return fmt.Errorf("duplicate feed name found: %s (in %s and %s)", feed.Name, source, feed.Source.File)
 		}
-		if feed.URL == "" {
-			return fmt.Errorf("feed URL is required")
+		return fmt.Errorf("duplicate feed name found: %s", feed.Name)
+	}
+	names[feed.Name] = feed.Source.File
+
+	// 检查 URL 和名称组合的唯一性
+	pair := feed.Name + "|" + feed.URL
+	if urlNamePairs[pair] {
+		return fmt.Errorf("duplicate feed name and URL combination found: %s", pair)
+	}
+	urlNamePairs[pair] = true
+
+	// 检查免打扰时段
+	if err := feed.QuietHours.Validate(); err != nil {
+		return fmt.Errorf("feed %s: quiet_hours: %w", feed.Name, err)
+	}
+
+	// 检查频道选项只针对已配置的频道
+	for channel, options := range feed.ChannelOptions {
+		if !containsString(feed.Channels, channel) {
+			return fmt.Errorf("feed %s: channel_options for unknown channel %s", feed.Name, channel)
 		}
-		if len(feed.Channels) == 0 {
-			return fmt.Errorf("feed %s must have at least one channel", feed.Name)
+		if err := options.QuietHours.Validate(); err != nil {
+			return fmt.Errorf("feed %s: channel %s: quiet_hours: %w", feed.Name, channel, err)
 		}
+	}
 
-		// 检查名称唯一性
-		if names[feed.Name] {
-			return fmt.Errorf("duplicate feed name found: %s", feed.Name)
+	// 检查按钮
+	for i, button := range feed.Buttons {
+		if button.Text == "" || button.URL == "" {
+			return fmt.Errorf("feed %s: button %d requires both text and url", feed.Name, i+1)
 		}
-		names[feed.Name] = true
+	}
+
+	// 检查更新处理方式
+	switch feed.OnUpdate {
+	case "", OnUpdateIgnore, OnUpdateEdit, OnUpdateReply:
+	default:
+		return fmt.Errorf("feed %s: invalid on_update %q", feed.Name, feed.OnUpdate)
+	}
+
+	// 检查移除处理方式
+	switch feed.OnRemoved {
+	case "", OnRemovedNone, OnRemovedDelete, OnRemovedExpire:
+	default:
+		return fmt.Errorf("feed %s: invalid on_removed %q", feed.Name, feed.OnRemoved)
+	}
+	if feed.RemovedAfter < 0 {
+		return fmt.Errorf("feed %s: removed_after must not be negative", feed.Name)
+	}
 
-		// 检查 URL 和名称组合的唯一性
-		pair := feed.Name + "|" + feed.URL
-		if urlNamePairs[pair] {
-			return fmt.Errorf("duplicate feed name and URL combination found: %s", pair)
+	// 检查推送方式
+	switch feed.Delivery {
+	case "", DeliveryInstant:
+	case DeliveryDigest:
+		if _, err := ParseSchedule(feed.Digest.Schedule, feed.Digest.Timezone); err != nil {
+			return fmt.Errorf("feed %s: digest: %w", feed.Name, err)
 		}
-		urlNamePairs[pair] = true
+	default:
+		return fmt.Errorf("feed %s: invalid delivery %q", feed.Name, feed.Delivery)
+	}
+
+	if feed.Tags.Limit < 0 {
+		return fmt.Errorf("feed %s: tags limit must not be negative", feed.Name)
+	}
 
-		// 检查模板
-		if feed.Template == "" {
-			// 设置默认模板
-			feed.Template = "📰 *{title}*\n\n{description}\n\n🔗 [阅读原文]({link})"
+	// 检查时区
+	if _, err := LoadLocation(feed.Timezone); err != nil {
+		return fmt.Errorf("feed %s: %w", feed.Name, err)
+	}
+
+	// 检查解析模式
+	switch feed.ParseMode {
+	case "", ParseModeMarkdown, ParseModeMarkdownV2, ParseModeHTML, ParseModeNone:
+	default:
+		return fmt.Errorf("feed %s: invalid parse_mode %q", feed.Name, feed.ParseMode)
+	}
+
+	// 检查模板引擎
+	switch feed.TemplateEngine {
+	case "", TemplateEngineBrace, TemplateEngineGo:
+	default:
+		return fmt.Errorf("feed %s: invalid template_engine %q", feed.Name, feed.TemplateEngine)
+	}
+	if err := validateTemplate(validate, feed.TemplateEngine, feed.Template); err != nil {
+		return fmt.Errorf("feed %s: template: %w", feed.Name, err)
+	}
+	for i, button := range feed.Buttons {
+		if err := validateTemplate(validate, feed.TemplateEngine, button.URL); err != nil {
+			return fmt.Errorf("feed %s: button %d url: %w", feed.Name, i+1, err)
 		}
 	}
+	if err := validateTemplate(validate, feed.TemplateEngine, feed.Digest.ItemTemplate); err != nil {
+		return fmt.Errorf("feed %s: digest item_template: %w", feed.Name, err)
+	}
 
 	return nil
 }
 
+// feedError 在错误前加上 feed 在配置文件中的位置和序号，例如 config.yaml:12:5: feeds[2]: ...
+func feedError(index int, feed FeedConfig, err error) error {
+	if feed.Source.File == "" {
+		return fmt.Errorf("feeds[%d]: %w", index, err)
+	}
+	return fmt.Errorf("%s: %w", feed.Source, err)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // 配置文件自动监听Manager 配置管理器
 type Manager struct {
 	sync.RWMutex
+	// 串行化 Load，避免收到 SIGHUP 和文件变化同时重新加载时，先读取的旧配置覆盖后读取的新配置
+	loadMu sync.Mutex
+
 	config    *Config
 	filepath  string
 	watcher   *fsnotify.Watcher
 	callbacks []func(*Config)
+	// 检查模板的函数
+	validate TemplateValidator
+	// 重新加载失败时的回调
+	errorCallbacks []func(error)
+
+	// 当前配置需要监控的文件和目录（配置文件、include 的文件、模板文件）
+	watchPaths []string
+	// 当前配置的 include 路径和 glob，监控其所在目录
+	includes []string
+	// 需要监控的文件及其实际路径（解析符号链接后）
+	files map[string]string
+	// 其中配置文件的新增、删除需要重新加载的目录
+	dirs map[string]bool
+	// 已添加监控的目录
+	watchedDirs map[string]bool
+
+	// 文件事件停止后等待多久再重新加载，合并编辑器保存时产生的多个事件
+	reloadDelay time.Duration
 }
 
-// NewManager 创建新的配置管理器
-func NewManager(filepath string) (*Manager, error) {
+// DefaultReloadDelay 默认的重新加载等待时间
+const DefaultReloadDelay = 500 * time.Millisecond
+
+// NewManager 创建新的配置管理器，path 可以是配置文件或配置目录，模板使用 validate 检查
+func NewManager(path string, validate TemplateValidator) (*Manager, error) {
 	m := &Manager{
-		filepath:  filepath,
-		callbacks: make([]func(*Config), 0),
+		filepath:    path,
+		validate:    validate,
+		callbacks:   make([]func(*Config), 0),
+		reloadDelay: DefaultReloadDelay,
 	}
 
 	// 初始加载配置
@@ -115,47 +460,211 @@ func NewManager(filepath string) (*Manager, error) {
 	// 启动监控协程
 	go m.watchConfig()
 
-	// 添加文件监控
-	if err := watcher.Add(filepath); err != nil {
+	// 添加文件监控。监控所在目录而不是文件本身，
+	// 编辑器保存（写入临时文件后重命名）和 Kubernetes ConfigMap 更新（替换符号链接）后文件被替换，对文件本身的监控会失效
+	dir := watchDir(path)
+	if err := watcher.Add(dir); err != nil {
 		watcher.Close()
 		return nil, err
 	}
+	m.Lock()
+	m.watchedDirs = map[string]bool{dir: true}
+	m.Unlock()
+	m.updateWatches()
 
 	return m, nil
 }
 
-// Load 加载配置文件
-func (m *Manager) Load() error {
-	data, err := os.ReadFile(m.filepath)
+// watchDir 返回监控 path 需要添加的目录，path 为目录时返回本身，否则返回所在目录
+func watchDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Clean(path)
+	}
+	return filepath.Dir(filepath.Clean(path))
+}
+
+// LoadFile 读取并验证配置文件或配置目录，模板使用 validate 检查
+func LoadFile(path string, validate TemplateValidator) (*Config, error) {
+	cfg, _, err := loadFile(path, validate)
+	return cfg, err
+}
+
+// Check 读取并验证配置文件或配置目录，同时返回不影响加载的警告，例如不支持的配置项
+// 配置无效时也返回已发现的警告
+func Check(path string, validate TemplateValidator) (*Config, []string, error) {
+	cfg, result, err := loadFile(path, validate)
+	return cfg, result.warnings, err
+}
+
+// loadResult 加载配置时收集的信息
+type loadResult struct {
+	watch    []string // 需要监控的文件和目录
+	includes []string // include 的路径和 glob，新增、删除匹配的文件时需要重新加载
+	warnings []string // 不影响加载的问题，例如不支持的配置项
+}
+
+// loadFile 读取并验证配置文件或配置目录
+func loadFile(path string, validate TemplateValidator) (*Config, loadResult, error) {
+	var result loadResult
+	cfg, err := readConfig(path, &result)
 	if err != nil {
-		return err
+		return nil, result, err
 	}
 
-	var newConfig Config
-	if err := yaml.Unmarshal(data, &newConfig); err != nil {
-		return err
+	// 环境变量覆盖配置项，并读取密钥文件
+	if err := cfg.applyEnvOverrides(); err != nil {
+		return nil, result, err
+	}
+	baseDir := BaseDir(path)
+	if err := cfg.loadSecrets(baseDir); err != nil {
+		return nil, result, err
 	}
 
+	// 应用默认设置，之后加载共享模板和模板文件
+	cfg.applyDefaults()
+	templateFiles, err := cfg.ResolveTemplates(baseDir)
+	if err != nil {
+		return nil, result, err
+	}
+	result.watch = uniquePaths(append(result.watch, templateFiles...))
+
 	// 验证配置
-	if err := newConfig.Validate(); err != nil {
+	if err := cfg.Validate(validate); err != nil {
+		return nil, result, err
+	}
+	return cfg, result, nil
+}
+
+// Load 加载配置文件，也用于收到 SIGHUP 等信号时手动重新加载
+// 同一时间只执行一次加载，回调在加载过程中调用，不能在回调中调用 Load
+func (m *Manager) Load() error {
+	m.loadMu.Lock()
+	defer m.loadMu.Unlock()
+
+	newConfig, result, err := loadFile(m.filepath, m.validate)
+	if err != nil {
+		m.RLock()
+		callbacks := make([]func(error), len(m.errorCallbacks))
+		copy(callbacks, m.errorCallbacks)
+		m.RUnlock()
+		for _, cb := range callbacks {
+			cb(err)
+		}
 		return err
 	}
+	for _, warning := range result.warnings {
+		log.Printf("Config Warning: %s", warning)
+	}
 
 	m.Lock()
-	m.config = &newConfig
+	m.config = newConfig
+	m.watchPaths = result.watch
+	m.includes = result.includes
 	callbacks := make([]func(*Config), len(m.callbacks))
 	copy(callbacks, m.callbacks)
 	m.Unlock()
 
 	// 通知所有订阅者
 	for _, cb := range callbacks {
-		cb(&newConfig)
+		cb(newConfig)
 	}
 
+	m.updateWatches()
+
 	log.Printf("Config Reloaded: %s", m.filepath)
 	return nil
 }
 
+// updateWatches 按当前配置使用的文件和目录更新监控
+// 文件通过所在目录监控，并记录解析符号链接后的实际路径，用于发现符号链接的替换
+func (m *Manager) updateWatches() {
+	m.Lock()
+	defer m.Unlock()
+
+	files := make(map[string]string)
+	dirs := make(map[string]bool)
+	for _, path := range m.watchPaths {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			dirs[path] = true
+			continue
+		}
+		files[path] = realPath(path)
+	}
+	m.files, m.dirs = files, dirs
+	if m.watcher == nil {
+		return
+	}
+
+	wanted := make(map[string]bool)
+	watchDirs := make([]string, 0, len(m.watchPaths)+len(m.includes))
+	for _, path := range m.watchPaths {
+		watchDirs = append(watchDirs, watchDir(path))
+	}
+	for _, pattern := range m.includes {
+		watchDirs = append(watchDirs, filepath.Dir(pattern))
+	}
+	for _, dir := range watchDirs {
+		if wanted[dir] {
+			continue
+		}
+		wanted[dir] = true
+		if m.watchedDirs[dir] {
+			continue
+		}
+		if err := m.watcher.Add(dir); err != nil {
+			log.Printf("Config Monitor Error: %v", err)
+			delete(wanted, dir)
+		}
+	}
+	for dir := range m.watchedDirs {
+		if !wanted[dir] {
+			m.watcher.Remove(dir)
+		}
+	}
+	m.watchedDirs = wanted
+}
+
+// realPath 返回解析符号链接后的路径，文件不存在时返回空字符串
+func realPath(path string) string {
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return ""
+	}
+	return real
+}
+
+// shouldReload 判断文件事件是否需要重新加载配置
+func (m *Manager) shouldReload(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+
+	m.RLock()
+	defer m.RUnlock()
+	// 监控的文件被修改、创建、删除或重命名
+	if _, ok := m.files[name]; ok {
+		return true
+	}
+	// 配置目录中新增、删除配置文件
+	if isConfigFile(name) && m.dirs[filepath.Dir(name)] {
+		return true
+	}
+	// 新增、删除 include 匹配的文件
+	for _, pattern := range m.includes {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	// 符号链接指向的文件被替换，例如 Kubernetes ConfigMap 更新时替换 ..data 链接
+	for path, real := range m.files {
+		if realPath(path) != real {
+			return true
+		}
+	}
+	return false
+}
+
 // Get 获取当前配置
 func (m *Manager) Get() *Config {
 	m.RLock()
@@ -170,18 +679,38 @@ func (m *Manager) OnConfigChange(callback func(*Config)) {
 	m.Unlock()
 }
 
-// watchConfig 监控配置文件变化
+// OnReloadError 注册配置重新加载失败的回调函数，加载失败时继续使用当前配置
+func (m *Manager) OnReloadError(callback func(error)) {
+	m.Lock()
+	m.errorCallbacks = append(m.errorCallbacks, callback)
+	m.Unlock()
+}
+
+// watchConfig 监控配置文件变化，事件停止 reloadDelay 后重新加载一次
 func (m *Manager) watchConfig() {
+	timer := time.NewTimer(m.reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
 	for {
 		select {
 		case event, ok := <-m.watcher.Events:
 			if !ok {
 				return
 			}
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				if err := m.Load(); err != nil {
-					log.Printf("Config Reload Error: %v", err)
+			if m.shouldReload(event) {
+				// 重新计时
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
 				}
+				timer.Reset(m.reloadDelay)
+			}
+		case <-timer.C:
+			if err := m.Load(); err != nil {
+				log.Printf("Config Reload Error: %v", err)
 			}
 		case err, ok := <-m.watcher.Errors:
 			if !ok {
@@ -0,0 +1,342 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// feedConfig 返回只包含一个 feed 的配置内容
+func feedConfig(name string) string {
+	return telegramConfig + `
+feeds:
+  - name: ` + name + `
+    url: https://example.com/rss.xml
+    channels: ["@a"]
+`
+}
+
+// waitForFeed 等待加载到第一个 feed 名称为 name 的配置
+func waitForFeed(t *testing.T, reloaded <-chan string, name string) {
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-reloaded:
+			if got == name {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("config was not reloaded with feed %s", name)
+		}
+	}
+}
+
+func newTestManager(t *testing.T, path string) (*Manager, <-chan string) {
+	m, err := NewManager(path, nil)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { m.Close() })
+
+	reloaded := make(chan string, 16)
+	m.OnConfigChange(func(cfg *Config) {
+		select {
+		case reloaded <- cfg.Feeds[0].Name:
+		default:
+		}
+	})
+	return m, reloaded
+}
+
+func TestManagerReloadsAfterAtomicSave(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(feedConfig("a")), 0644))
+	_, reloaded := newTestManager(t, configPath)
+
+	// 编辑器保存：写入临时文件后重命名覆盖原文件，之后的修改也应继续生效
+	for _, name := range []string{"b", "c"} {
+		tmp := filepath.Join(dir, ".config.yaml.tmp")
+		assert.NoError(t, os.WriteFile(tmp, []byte(feedConfig(name)), 0644))
+		assert.NoError(t, os.Rename(tmp, configPath))
+		waitForFeed(t, reloaded, name)
+	}
+}
+
+func TestManagerReloadsAfterSymlinkSwap(t *testing.T) {
+	// Kubernetes ConfigMap 的目录结构：config.yaml -> ..data/config.yaml，..data -> 带版本的目录
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"v1/config.yaml": feedConfig("a"),
+		"v2/config.yaml": feedConfig("b"),
+	})
+	assert.NoError(t, os.Symlink("v1", filepath.Join(dir, "..data")))
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.Symlink(filepath.Join("..data", "config.yaml"), configPath))
+	m, reloaded := newTestManager(t, configPath)
+	assert.Equal(t, "a", m.Get().Feeds[0].Name)
+
+	// 原子替换 ..data 链接
+	tmp := filepath.Join(dir, "..data_tmp")
+	assert.NoError(t, os.Symlink("v2", tmp))
+	assert.NoError(t, os.Rename(tmp, filepath.Join(dir, "..data")))
+	waitForFeed(t, reloaded, "b")
+}
+
+func TestManagerDebouncesReloads(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(feedConfig("a")), 0644))
+	_, reloaded := newTestManager(t, configPath)
+
+	// 连续多次写入只重新加载一次
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, os.WriteFile(configPath, []byte(feedConfig("b")), 0644))
+	}
+	waitForFeed(t, reloaded, "b")
+
+	select {
+	case name := <-reloaded:
+		t.Fatalf("unexpected extra reload: %s", name)
+	case <-time.After(2 * DefaultReloadDelay):
+	}
+}
+
+func TestManagerSerializesLoad(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(feedConfig("a")), 0644))
+	m, _ := newTestManager(t, configPath)
+
+	// 同时重新加载（如 SIGHUP 和文件变化），回调不会交叉执行
+	var active, overlapped int32
+	m.OnConfigChange(func(cfg *Config) {
+		if atomic.AddInt32(&active, 1) > 1 {
+			atomic.StoreInt32(&overlapped, 1)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, m.Load())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(0), atomic.LoadInt32(&overlapped))
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		feeds    string
+		warnings []string
+		err      string
+	}{
+		{
+			name: "不支持的配置项",
+			feeds: `
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a"]
+    disable_notifcation: true
+    tempalte: "{title}"
+`,
+			warnings: []string{`config.yaml:9: unknown field "disable_notifcation"`, `config.yaml:10: unknown field "tempalte"`},
+		},
+		{
+			name: "错误的位置和序号",
+			feeds: `
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a"]
+  - name: b
+    url: https://example.com/b.xml
+`,
+			err: "config.yaml:9:5: feeds[1]: feed b must have at least one channel",
+		},
+		{
+			name: "无效的 URL",
+			feeds: `
+  - name: a
+    url: example.com/a.xml
+    channels: ["@a"]
+`,
+			err: `feed a: invalid URL "example.com/a.xml"`,
+		},
+		{
+			name: "无效的频道",
+			feeds: `
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a", "t.me/b"]
+`,
+			err: `feed a: invalid channel "t.me/b"`,
+		},
+		{
+			name: "数字频道 ID",
+			feeds: `
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["-1001234567890"]
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.yaml")
+			assert.NoError(t, os.WriteFile(path, []byte(telegramConfig+"feeds:"+tt.feeds), 0644))
+
+			_, warnings, err := Check(path, nil)
+			var expected []string
+			for _, warning := range tt.warnings {
+				expected = append(expected, filepath.Join(dir, warning))
+			}
+			assert.Equal(t, expected, warnings)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestOptionsFor(t *testing.T) {
+	yes, no := true, false
+	quiet := &QuietHoursConfig{Start: "23:00", End: "07:00"}
+	feed := FeedConfig{
+		DeliveryOptions: DeliveryOptions{DisableNotification: &yes, QuietHours: quiet},
+		ChannelOptions: map[string]DeliveryOptions{
+			"@off":     {DisableNotification: &no},
+			"@preview": {DisableWebPagePreview: &yes, ProtectContent: &no},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		channel string
+		want    DeliveryOptions
+	}{
+		{"没有频道设置时使用 feed 设置", "@other", DeliveryOptions{DisableNotification: &yes, QuietHours: quiet}},
+		{"频道设置为 false 覆盖 feed 的 true", "@off", DeliveryOptions{DisableNotification: &no, QuietHours: quiet}},
+		{"频道只覆盖已设置的字段", "@preview", DeliveryOptions{DisableNotification: &yes, DisableWebPagePreview: &yes, ProtectContent: &no, QuietHours: quiet}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, feed.OptionsFor(tt.channel))
+		})
+	}
+}
+
+func TestDeliveryOptionsMerge(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name     string
+		base     DeliveryOptions
+		override DeliveryOptions
+		want     DeliveryOptions
+	}{
+		{"都未设置", DeliveryOptions{}, DeliveryOptions{}, DeliveryOptions{}},
+		{"未设置的字段保留原值", DeliveryOptions{ProtectContent: &yes}, DeliveryOptions{}, DeliveryOptions{ProtectContent: &yes}},
+		{"false 覆盖 true", DeliveryOptions{ProtectContent: &yes}, DeliveryOptions{ProtectContent: &no}, DeliveryOptions{ProtectContent: &no}},
+		{"true 覆盖 false", DeliveryOptions{DisableWebPagePreview: &no}, DeliveryOptions{DisableWebPagePreview: &yes}, DeliveryOptions{DisableWebPagePreview: &yes}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.base.Merge(tt.override))
+		})
+	}
+}
+
+func TestValidatePositions(t *testing.T) {
+	feeds := "feeds:\n  - name: a\n    url: https://example.com/a.xml\n    channels: [\"@a\"]\n"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		path  string // 加载的配置文件，默认 config.yaml
+		err   string // 相对于临时目录的错误信息
+	}{
+		{
+			name:  "缺少 Bot Token",
+			files: map[string]string{"config.yaml": "timezone: UTC\ntelegram:\n  check_interval: 300\n" + feeds},
+			err:   "config.yaml:2:1: telegram bot token is required",
+		},
+		{
+			name:  "无效的检查间隔",
+			files: map[string]string{"config.yaml": "telegram:\n  bot_token: token\n  check_interval: 0\n" + feeds},
+			err:   "config.yaml:1:1: telegram check interval must be positive",
+		},
+		{
+			name:  "无效的 admin_chat",
+			files: map[string]string{"config.yaml": telegramConfig + "  admin_chat: t.me/admin\n" + feeds},
+			err:   `config.yaml:2:1: telegram admin_chat "t.me/admin": expected @username or numeric chat ID`,
+		},
+		{
+			name:  "无效的时区",
+			files: map[string]string{"config.yaml": telegramConfig + "timezone: Mars/Olympus\n" + feeds},
+			err:   `config.yaml:5:1: timezone: invalid timezone "Mars/Olympus"`,
+		},
+		{
+			name:  "共享模板同时设置 template 和 file",
+			files: map[string]string{"config.yaml": telegramConfig + "templates:\n  short: \"{title}\"\n  news:\n    template: \"{title}\"\n    file: news.tmpl\n" + feeds},
+			err:   "config.yaml:7:3: template news: template and file are mutually exclusive",
+		},
+		{
+			name: "include 的文件中的配置",
+			files: map[string]string{
+				"config.yaml": "include: [b.yaml]\n" + feeds,
+				"b.yaml":      "telegram:\n  bot_token: token\n",
+			},
+			err: "b.yaml:1:1: telegram check interval must be positive",
+		},
+		{
+			name: "配置目录",
+			files: map[string]string{
+				"conf/a.yaml": telegramConfig + feeds,
+				"conf/b.yaml": "\ntimezone: Mars/Olympus\n",
+			},
+			path: "conf",
+			err:  `conf/b.yaml:2:1: timezone: invalid timezone "Mars/Olympus"`,
+		},
+		{
+			name:  "TOML 配置没有行号",
+			files: map[string]string{"config.toml": "[telegram]\ncheck_interval = 300\n"},
+			path:  "config.toml",
+			err:   "config.toml: telegram bot token is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			path := tt.path
+			if path == "" {
+				path = "config.yaml"
+			}
+			_, err := LoadFile(filepath.Join(dir, path), nil)
+			assert.ErrorContains(t, err, filepath.Join(dir, tt.err))
+		})
+	}
+
+	// 来自环境变量的时区没有配置文件中的位置
+	t.Setenv(EnvTimezone, "Mars/Olympus")
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.yaml": telegramConfig + "timezone: UTC\n" + feeds})
+	_, err := LoadFile(filepath.Join(dir, "config.yaml"), nil)
+	assert.ErrorContains(t, err, "timezone: invalid timezone")
+	assert.NotContains(t, err.Error(), "config.yaml")
+}
@@ -0,0 +1,108 @@
+package config
+
+//feed 默认设置
+//defaults 中的设置应用到所有 feed，feed 中设置的同名项覆盖默认值
+
+// 没有设置模板时使用的默认模板，markdown 和 markdownv2 解析模式使用
+const (
+	DefaultTemplate   = "📰 *{title}*\n\n{description}\n\n🔗 [阅读原文]({link})"          // 花括号语法
+	DefaultGoTemplate = "📰 *{{.Title}}*\n\n{{.Description}}\n\n🔗 [阅读原文]({{.Link}})" // Go 模板引擎
+)
+
+// html 和 none 解析模式的默认模板
+const (
+	defaultHTMLTemplate    = "📰 <b>{title}</b>\n\n{description}\n\n🔗 <a href=\"{link}\">阅读原文</a>"
+	defaultGoHTMLTemplate  = "📰 <b>{{.Title}}</b>\n\n{{.Description}}\n\n🔗 <a href=\"{{.Link}}\">阅读原文</a>"
+	defaultPlainTemplate   = "📰 {title}\n\n{description}\n\n🔗 {link}"
+	defaultGoPlainTemplate = "📰 {{.Title}}\n\n{{.Description}}\n\n🔗 {{.Link}}"
+)
+
+// DefaultTemplateFor 返回模板引擎和解析模式对应的默认模板
+func DefaultTemplateFor(engine, parseMode string) string {
+	goEngine := engine == TemplateEngineGo
+	switch {
+	case parseMode == ParseModeHTML && goEngine:
+		return defaultGoHTMLTemplate
+	case parseMode == ParseModeHTML:
+		return defaultHTMLTemplate
+	case parseMode == ParseModeNone && goEngine:
+		return defaultGoPlainTemplate
+	case parseMode == ParseModeNone:
+		return defaultPlainTemplate
+	case goEngine:
+		return DefaultGoTemplate
+	}
+	return DefaultTemplate
+}
+
+// 消息解析模式
+const (
+	ParseModeMarkdown   = "markdown" // 默认
+	ParseModeMarkdownV2 = "markdownv2"
+	ParseModeHTML       = "html"
+	ParseModeNone       = "none" // 纯文本
+)
+
+// FeedDefaults 所有 feed 共用的默认设置
+type FeedDefaults struct {
+	Template                       string   `yaml:"template"`
+	TemplateEngine                 string   `yaml:"template_engine"`
+	Channels                       []string `yaml:"channels"`
+	ArticleExpirationDurationHours *int     `yaml:"article_expiration_duration_hours"`
+	ParseMode                      string   `yaml:"parse_mode"`
+
+	// 发送选项，feed 中已设置的字段覆盖默认值
+	DeliveryOptions `yaml:",inline"`
+}
+
+// SameTemplateEngine 判断两个模板引擎设置是否相同，未设置时为花括号语法
+func SameTemplateEngine(a, b string) bool {
+	if a == "" {
+		a = TemplateEngineBrace
+	}
+	if b == "" {
+		b = TemplateEngineBrace
+	}
+	return a == b
+}
+
+// FeedWithDefaults 返回应用 defaults 后的 feed 配置，用于配置之外的源（如 preview 的 URL）
+// 与加载配置时相同，模板中的 @name 引用和模板变量需要再由 ResolveTemplates 解析
+func (c *Config) FeedWithDefaults(feed FeedConfig) FeedConfig {
+	tmp := Config{Defaults: c.Defaults, Feeds: []FeedConfig{feed}}
+	tmp.applyDefaults()
+	return tmp.Feeds[0]
+}
+
+// applyDefaults 将 defaults 中的设置应用到各 feed，并为没有模板的 feed 设置默认模板
+// 在加载共享模板之前调用，defaults 中的模板同样支持 @name 引用和模板变量
+func (c *Config) applyDefaults() {
+	d := c.Defaults
+	// 使用索引修改，range 的副本修改不会保存
+	for i := range c.Feeds {
+		feed := &c.Feeds[i]
+		// 模板引擎只随模板一起继承，feed 自己的模板仍按 feed 的引擎解析；
+		// feed 设置了不同的模板引擎时不继承 defaults 的模板，使用该引擎的默认模板
+		if feed.Template == "" && feed.TemplateFile == "" &&
+			(feed.TemplateEngine == "" || SameTemplateEngine(feed.TemplateEngine, d.TemplateEngine)) {
+			feed.Template = d.Template
+			if feed.TemplateEngine == "" {
+				feed.TemplateEngine = d.TemplateEngine
+			}
+		}
+		if len(feed.Channels) == 0 {
+			feed.Channels = append([]string(nil), d.Channels...)
+		}
+		if feed.ArticleExpirationDurationHours == nil {
+			feed.ArticleExpirationDurationHours = d.ArticleExpirationDurationHours
+		}
+		if feed.ParseMode == "" {
+			feed.ParseMode = d.ParseMode
+		}
+		feed.DeliveryOptions = d.DeliveryOptions.Merge(feed.DeliveryOptions)
+
+		if feed.Template == "" && feed.TemplateFile == "" {
+			feed.Template = DefaultTemplateFor(feed.TemplateEngine, feed.ParseMode)
+		}
+	}
+}
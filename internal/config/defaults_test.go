@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(telegramConfig+`
+templates:
+  short: "{title}"
+defaults:
+  template: "@short"
+  channels: ["@news"]
+  article_expiration_duration_hours: 48
+  parse_mode: html
+  disable_notification: true
+feeds:
+  - name: inherit
+    url: https://example.com/a.xml
+  - name: override
+    url: https://example.com/b.xml
+    channels: ["@other"]
+    template: "{title} {link}"
+    article_expiration_duration_hours: 12
+    parse_mode: markdownv2
+    disable_notification: false
+    protect_content: true
+`), 0644))
+
+	cfg, err := LoadFile(path, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	inherit := cfg.Feeds[0]
+	assert.Equal(t, "{title}", inherit.Template)
+	assert.Equal(t, []string{"@news"}, inherit.Channels)
+	assert.Equal(t, 48, *inherit.ArticleExpirationDurationHours)
+	assert.Equal(t, ParseModeHTML, inherit.ParseMode)
+	assert.True(t, *inherit.DisableNotification)
+	assert.Nil(t, inherit.ProtectContent)
+
+	override := cfg.Feeds[1]
+	assert.Equal(t, "{title} {link}", override.Template)
+	assert.Equal(t, []string{"@other"}, override.Channels)
+	assert.Equal(t, 12, *override.ArticleExpirationDurationHours)
+	assert.Equal(t, ParseModeMarkdownV2, override.ParseMode)
+	assert.False(t, *override.DisableNotification)
+	assert.True(t, *override.ProtectContent)
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	cfg := Config{
+		Defaults: FeedDefaults{TemplateEngine: TemplateEngineGo},
+		Feeds: []FeedConfig{
+			{Name: "brace", TemplateEngine: TemplateEngineBrace},
+			{Name: "file", TemplateFile: "news.tmpl"},
+			{Name: "go", TemplateEngine: TemplateEngineGo},
+			{Name: "own", Template: "{title}"},
+			{Name: "inherit-engine"},
+			{Name: "html", TemplateEngine: TemplateEngineBrace, ParseMode: ParseModeHTML},
+		},
+	}
+	cfg.applyDefaults()
+
+	// 默认模板保存到配置中
+	assert.Equal(t, DefaultTemplate, cfg.Feeds[0].Template)
+	assert.Equal(t, "", cfg.Feeds[1].Template)
+	// Go 模板引擎使用 Go 语法的默认模板
+	assert.Equal(t, DefaultGoTemplate, cfg.Feeds[2].Template)
+	// 模板引擎只随模板一起继承
+	assert.Equal(t, "", cfg.Feeds[3].TemplateEngine)
+	assert.Equal(t, TemplateEngineGo, cfg.Feeds[4].TemplateEngine)
+	assert.Equal(t, DefaultGoTemplate, cfg.Feeds[4].Template)
+	// 默认模板随解析模式变化
+	assert.Equal(t, DefaultTemplateFor(TemplateEngineBrace, ParseModeHTML), cfg.Feeds[5].Template)
+}
+
+func TestDefaultTemplateEngineMismatch(t *testing.T) {
+	cfg := Config{
+		Defaults: FeedDefaults{Template: "{{.Title}}", TemplateEngine: TemplateEngineGo},
+		Feeds: []FeedConfig{
+			{Name: "inherit"},
+			{Name: "go", TemplateEngine: TemplateEngineGo},
+			{Name: "brace", TemplateEngine: TemplateEngineBrace},
+			{Name: "html", TemplateEngine: TemplateEngineBrace, ParseMode: ParseModeHTML},
+		},
+	}
+	cfg.applyDefaults()
+
+	// 模板引擎相同或未设置时继承 defaults 的模板
+	assert.Equal(t, "{{.Title}}", cfg.Feeds[0].Template)
+	assert.Equal(t, TemplateEngineGo, cfg.Feeds[0].TemplateEngine)
+	assert.Equal(t, "{{.Title}}", cfg.Feeds[1].Template)
+	// 模板引擎不同时使用该引擎的默认模板
+	assert.Equal(t, DefaultTemplate, cfg.Feeds[2].Template)
+	assert.Equal(t, TemplateEngineBrace, cfg.Feeds[2].TemplateEngine)
+	assert.Equal(t, DefaultTemplateFor(TemplateEngineBrace, ParseModeHTML), cfg.Feeds[3].Template)
+
+	// defaults 未设置模板引擎时为花括号语法
+	cfg = Config{
+		Defaults: FeedDefaults{Template: "{title}"},
+		Feeds:    []FeedConfig{{Name: "brace", TemplateEngine: TemplateEngineBrace}, {Name: "go", TemplateEngine: TemplateEngineGo}},
+	}
+	cfg.applyDefaults()
+	assert.Equal(t, "{title}", cfg.Feeds[0].Template)
+	assert.Equal(t, DefaultGoTemplate, cfg.Feeds[1].Template)
+}
+
+func TestDefaultTemplateFor(t *testing.T) {
+	tests := []struct {
+		engine, parseMode string
+		expected          string
+	}{
+		{"", "", DefaultTemplate},
+		{TemplateEngineBrace, ParseModeMarkdownV2, DefaultTemplate},
+		{TemplateEngineGo, ParseModeMarkdown, DefaultGoTemplate},
+		{TemplateEngineBrace, ParseModeHTML, defaultHTMLTemplate},
+		{TemplateEngineGo, ParseModeHTML, defaultGoHTMLTemplate},
+		{"", ParseModeNone, defaultPlainTemplate},
+		{TemplateEngineGo, ParseModeNone, defaultGoPlainTemplate},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, DefaultTemplateFor(tt.engine, tt.parseMode), "%s %s", tt.engine, tt.parseMode)
+	}
+}
+
+func TestInvalidParseMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(telegramConfig+`
+defaults:
+  parse_mode: markdown2
+feeds:
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a"]
+`), 0644))
+
+	_, err := LoadFile(path, nil)
+	assert.ErrorContains(t, err, `feed a: invalid parse_mode "markdown2"`)
+}
@@ -0,0 +1,83 @@
+package config
+
+//配置变化摘要
+//按名称比较两次配置中的 feed，用于重新加载配置后通知管理员
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// 摘要中每类变化最多列出的 feed 数量
+const maxDiffNames = 10
+
+// FeedDiff 两次配置之间 feed 的变化
+type FeedDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty 判断 feed 是否没有变化
+func (d FeedDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String 返回变化摘要，例如 "新增：a, b\n移除：c"
+func (d FeedDiff) String() string {
+	if d.Empty() {
+		return "源没有变化"
+	}
+	var lines []string
+	for _, part := range []struct {
+		label string
+		names []string
+	}{
+		{"新增", d.Added},
+		{"移除", d.Removed},
+		{"修改", d.Changed},
+	} {
+		if len(part.names) > 0 {
+			lines = append(lines, part.label+"："+joinNames(part.names))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// joinNames 连接名称，超过 maxDiffNames 个时省略其余名称
+func joinNames(names []string) string {
+	if len(names) <= maxDiffNames {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s 等 %d 个", strings.Join(names[:maxDiffNames], ", "), len(names))
+}
+
+// DiffFeeds 按名称比较两次配置中的 feed，新增和修改按 newCfg 中的顺序，移除按 oldCfg 中的顺序
+// feed 在配置文件中的位置变化不视为修改
+func DiffFeeds(oldCfg, newCfg *Config) FeedDiff {
+	oldFeeds := make(map[string]FeedConfig)
+	for _, feed := range oldCfg.Feeds {
+		feed.Source = FeedSource{}
+		oldFeeds[feed.Name] = feed
+	}
+
+	var diff FeedDiff
+	seen := make(map[string]bool)
+	for _, feed := range newCfg.Feeds {
+		feed.Source = FeedSource{}
+		seen[feed.Name] = true
+		old, ok := oldFeeds[feed.Name]
+		if !ok {
+			diff.Added = append(diff.Added, feed.Name)
+		} else if !reflect.DeepEqual(old, feed) {
+			diff.Changed = append(diff.Changed, feed.Name)
+		}
+	}
+	for _, feed := range oldCfg.Feeds {
+		if !seen[feed.Name] {
+			diff.Removed = append(diff.Removed, feed.Name)
+		}
+	}
+	return diff
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFeeds(t *testing.T) {
+	oldCfg := &Config{Feeds: []FeedConfig{
+		{Name: "a", URL: "https://example.com/a.xml", Source: FeedSource{Line: 1}},
+		{Name: "b", URL: "https://example.com/b.xml"},
+		{Name: "c", URL: "https://example.com/c.xml"},
+	}}
+	newCfg := &Config{Feeds: []FeedConfig{
+		{Name: "d", URL: "https://example.com/d.xml"},
+		// 只有位置变化
+		{Name: "a", URL: "https://example.com/a.xml", Source: FeedSource{Line: 5}},
+		{Name: "c", URL: "https://example.com/c2.xml"},
+	}}
+
+	diff := DiffFeeds(oldCfg, newCfg)
+	assert.Equal(t, FeedDiff{Added: []string{"d"}, Removed: []string{"b"}, Changed: []string{"c"}}, diff)
+	assert.Equal(t, "新增：d\n移除：b\n修改：c", diff.String())
+
+	assert.True(t, DiffFeeds(oldCfg, oldCfg).Empty())
+	assert.Equal(t, "源没有变化", DiffFeeds(oldCfg, oldCfg).String())
+}
+
+func TestFeedDiffStringLimit(t *testing.T) {
+	var diff FeedDiff
+	for i := 1; i <= 12; i++ {
+		diff.Added = append(diff.Added, fmt.Sprint(i))
+	}
+	assert.Equal(t, "新增：1, 2, 3, 4, 5, 6, 7, 8, 9, 10 等 12 个", diff.String())
+}
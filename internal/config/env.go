@@ -0,0 +1,169 @@
+package config
+
+//配置中的环境变量和密钥文件
+//字符串值中的 ${NAME}、${NAME:-默认值} 替换为环境变量，$${NAME} 保留为 ${NAME}
+//模板内容不替换，replace 操作符可以直接使用 ${1}、${name} 引用分组
+//RSS2TG_ 开头的环境变量覆盖对应的顶层配置项，bot_token_file 从文件读取 Bot Token
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 覆盖配置项的环境变量
+const (
+	EnvBotToken      = "RSS2TG_BOT_TOKEN"
+	EnvBotTokenFile  = "RSS2TG_BOT_TOKEN_FILE"
+	EnvCheckInterval = "RSS2TG_CHECK_INTERVAL"
+	EnvTimezone      = "RSS2TG_TIMEZONE"
+)
+
+// 环境变量引用，分组 1 为变量名，分组 2 为默认值
+var envVarRegex = regexp.MustCompile(`\$?\$\{([A-Za-z_][A-Za-z0-9_]*)(:-[^}]*)?\}`)
+
+// expandEnv 替换字符串中的环境变量引用，未设置且没有默认值的变量返回错误
+// 只替换 ${NAME} 形式，模板中 replace 操作符的 $1 等引用不受影响
+func expandEnv(s string) (string, error) {
+	var err error
+	result := envVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+		if strings.HasPrefix(match, "$$") {
+			return match[1:]
+		}
+		m := envVarRegex.FindStringSubmatch(match)
+		if value, ok := os.LookupEnv(m[1]); ok {
+			return value
+		}
+		if m[2] != "" {
+			return strings.TrimPrefix(m[2], ":-")
+		}
+		if err == nil {
+			err = fmt.Errorf("environment variable %s is not set", m[1])
+		}
+		return match
+	})
+	return result, err
+}
+
+// isEnvRef 判断值是否只包含一个环境变量引用
+func isEnvRef(s string) bool {
+	loc := envVarRegex.FindStringIndex(s)
+	return loc != nil && loc[0] == 0 && loc[1] == len(s) && !strings.HasPrefix(s, "$$")
+}
+
+// 内容为模板的配置项，不替换其中的环境变量引用
+var templateKeys = map[string]bool{
+	"template":      true,
+	"item_template": true,
+}
+
+// expandEnvNode 替换 YAML 节点中字符串值的环境变量引用，模板内容除外
+func expandEnvNode(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		expanded, err := expandEnv(node.Value)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		if expanded != node.Value {
+			wholeRef := isEnvRef(node.Value)
+			node.Value = expanded
+			// 未加引号的值按替换后的内容重新推断类型，例如 check_interval: ${INTERVAL}
+			if node.Style == 0 {
+				node.Tag = ""
+			} else if wholeRef {
+				// 整个值为一个环境变量引用时，加了引号（JSON 中必须加引号）也按数字和布尔值推断，
+				// 例如 "check_interval": "${INTERVAL}"；其他内容仍为字符串
+				inferred := yaml.Node{Kind: yaml.ScalarNode, Value: expanded}
+				switch inferred.ShortTag() {
+				case "!!int", "!!float", "!!bool":
+					node.Tag, node.Style = "", 0
+				}
+			}
+		}
+	case yaml.MappingNode:
+		// 只替换值，不替换键
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i].Value, node.Content[i+1]
+			if templateKeys[key] {
+				continue
+			}
+			if key == "templates" && value.Kind == yaml.MappingNode {
+				if err := expandSharedTemplates(value); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := expandEnvNode(value); err != nil {
+				return err
+			}
+		}
+	default:
+		for _, child := range node.Content {
+			if err := expandEnvNode(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandSharedTemplates 替换共享模板中的环境变量引用，直接使用字符串定义的模板内容不替换
+func expandSharedTemplates(node *yaml.Node) error {
+	for i := 1; i < len(node.Content); i += 2 {
+		if node.Content[i].Kind == yaml.ScalarNode {
+			continue
+		}
+		if err := expandEnvNode(node.Content[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEnvOverrides 使用 RSS2TG_ 环境变量覆盖顶层配置项
+func (c *Config) applyEnvOverrides() error {
+	if value, ok := os.LookupEnv(EnvBotToken); ok {
+		c.Telegram.BotToken, c.Telegram.BotTokenFile = value, ""
+	}
+	if value, ok := os.LookupEnv(EnvBotTokenFile); ok {
+		c.Telegram.BotToken, c.Telegram.BotTokenFile = "", value
+	}
+	if value, ok := os.LookupEnv(EnvCheckInterval); ok {
+		interval, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s: invalid check interval %q", EnvCheckInterval, value)
+		}
+		c.Telegram.CheckInterval = interval
+	}
+	if value, ok := os.LookupEnv(EnvTimezone); ok {
+		// 时区来自环境变量，错误信息中不使用配置文件的位置
+		c.Timezone, c.Positions.Timezone = value, Position{}
+	}
+	return nil
+}
+
+// loadSecrets 从 bot_token_file 读取 Bot Token，相对路径基于 baseDir
+func (c *Config) loadSecrets(baseDir string) error {
+	path := c.Telegram.BotTokenFile
+	if path == "" {
+		return nil
+	}
+	if c.Telegram.BotToken != "" {
+		return positionError(c.Positions.Telegram, fmt.Errorf("telegram bot_token and bot_token_file are mutually exclusive"))
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return positionError(c.Positions.Telegram, fmt.Errorf("telegram bot_token_file: %w", err))
+	}
+	c.Telegram.BotToken = strings.TrimSpace(string(data))
+	return nil
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("RSS2TG_TEST_NAME", "world")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"变量", "hello ${RSS2TG_TEST_NAME}", "hello world", false},
+		{"默认值", "${RSS2TG_TEST_UNSET:-fallback}", "fallback", false},
+		{"空默认值", "a${RSS2TG_TEST_UNSET:-}b", "ab", false},
+		{"已设置时忽略默认值", "${RSS2TG_TEST_NAME:-x}", "world", false},
+		{"转义", "$${RSS2TG_TEST_NAME}", "${RSS2TG_TEST_NAME}", false},
+		{"正则引用不替换", "{title|replace:(\\d+):$1 ${1}}", "{title|replace:(\\d+):$1 ${1}}", false},
+		{"未设置", "${RSS2TG_TEST_UNSET}", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := expandEnv(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestLoadFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("file-token\n"), 0600))
+
+	write := func(data string) string {
+		path := filepath.Join(dir, "config.yaml")
+		if !strings.Contains(data, "feeds:") {
+			data += "feeds:\n  - name: a\n    url: https://example.com/feed\n    channels: [\"@a\"]\n"
+		}
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+		return path
+	}
+
+	t.Run("环境变量替换", func(t *testing.T) {
+		t.Setenv("RSS2TG_TEST_TOKEN", "env-token")
+		t.Setenv("RSS2TG_TEST_INTERVAL", "120")
+		t.Setenv("RSS2TG_TEST_CHANNEL", "@news")
+		cfg, err := LoadFile(write(`
+telegram:
+  bot_token: "${RSS2TG_TEST_TOKEN}"
+  check_interval: ${RSS2TG_TEST_INTERVAL}
+feeds:
+  - name: a
+    url: https://example.com/feed
+    channels: ["${RSS2TG_TEST_CHANNEL}"]
+`), nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "env-token", cfg.Telegram.BotToken)
+		assert.Equal(t, 120, cfg.Telegram.CheckInterval)
+		assert.Equal(t, []string{"@news"}, cfg.Feeds[0].Channels)
+	})
+
+	t.Run("模板内容不替换", func(t *testing.T) {
+		t.Setenv("RSS2TG_TEST_FOOTER", "来自环境变量")
+		cfg, err := LoadFile(write(`
+telegram:
+  bot_token: abc
+  check_interval: 60
+templates:
+  short: '{title|replace:(?P<n>\d+):${n}号}'
+  full:
+    template: '{title|replace:(?P<price>\d+):${price}元} {var:footer}'
+    vars:
+      footer: ${RSS2TG_TEST_FOOTER}
+defaults:
+  template: '{title|replace:(\d+):${1}}'
+feeds:
+  - name: a
+    url: https://example.com/feed
+    channels: ["@a"]
+    template: '{title|replace:(?P<price>\d+):${price}元}'
+    delivery: digest
+    digest:
+      schedule: every 1h
+      item_template: '- {title|replace:(?P<price>\d+):${price}元}'
+  - name: b
+    url: https://example.com/feed
+    channels: ["@a"]
+    template: "@full"
+  - name: c
+    url: https://example.com/feed
+    channels: ["@a"]
+    template: "@short"
+  - name: d
+    url: https://example.com/feed
+    channels: ["@a"]
+`), nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, `{title|replace:(?P<price>\d+):${price}元}`, cfg.Feeds[0].Template)
+		assert.Equal(t, `- {title|replace:(?P<price>\d+):${price}元}`, cfg.Feeds[0].Digest.ItemTemplate)
+		// 模板变量的值仍然替换环境变量
+		assert.Equal(t, `{title|replace:(?P<price>\d+):${price}元} 来自环境变量`, cfg.Feeds[1].Template)
+		assert.Equal(t, `{title|replace:(?P<n>\d+):${n}号}`, cfg.Feeds[2].Template)
+		assert.Equal(t, `{title|replace:(\d+):${1}}`, cfg.Feeds[3].Template)
+	})
+
+	t.Run("未设置的变量", func(t *testing.T) {
+		_, err := LoadFile(write(`
+telegram:
+  bot_token: "${RSS2TG_TEST_UNSET}"
+`), nil)
+		assert.ErrorContains(t, err, "RSS2TG_TEST_UNSET")
+	})
+
+	t.Run("密钥文件", func(t *testing.T) {
+		cfg, err := LoadFile(write(`
+telegram:
+  bot_token_file: token
+  check_interval: 60
+`), nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "file-token", cfg.Telegram.BotToken)
+	})
+
+	t.Run("密钥文件与 bot_token 冲突", func(t *testing.T) {
+		_, err := LoadFile(write(`
+telegram:
+  bot_token: abc
+  bot_token_file: token
+`), nil)
+		assert.ErrorContains(t, err, "mutually exclusive")
+	})
+
+	t.Run("环境变量覆盖", func(t *testing.T) {
+		t.Setenv(EnvBotToken, "override-token")
+		t.Setenv(EnvCheckInterval, "30")
+		t.Setenv(EnvTimezone, "Asia/Shanghai")
+		cfg, err := LoadFile(write(`
+telegram:
+  bot_token_file: token
+  check_interval: 60
+timezone: UTC
+`), nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, "override-token", cfg.Telegram.BotToken)
+		assert.Equal(t, 30, cfg.Telegram.CheckInterval)
+		assert.Equal(t, "Asia/Shanghai", cfg.Timezone)
+	})
+
+	t.Run("无效的检查间隔", func(t *testing.T) {
+		t.Setenv(EnvCheckInterval, "soon")
+		_, err := LoadFile(write(`
+telegram:
+  bot_token: abc
+`), nil)
+		assert.ErrorContains(t, err, EnvCheckInterval)
+	})
+}
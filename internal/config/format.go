@@ -0,0 +1,114 @@
+package config
+
+//配置文件格式
+//按扩展名选择格式：.yaml/.yml（默认）、.json、.toml
+//JSON 检查语法后转换为等价的 YAML 再解析，保留行号；TOML 解析后转换为 YAML 节点，之后的环境变量替换、合并和验证与 YAML 相同
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// 配置目录中读取的文件扩展名
+var configExts = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+	".toml": true,
+}
+
+// isConfigFile 判断是否为支持的配置文件
+func isConfigFile(name string) bool {
+	return configExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// isTOML 判断是否为 TOML 配置文件
+func isTOML(name string) bool {
+	return strings.ToLower(filepath.Ext(name)) == ".toml"
+}
+
+// isJSON 判断是否为 JSON 配置文件
+func isJSON(name string) bool {
+	return strings.ToLower(filepath.Ext(name)) == ".json"
+}
+
+// parseConfigNode 按扩展名将配置文件解析为 YAML 节点，其他扩展名按 YAML 解析
+func parseConfigNode(path string, data []byte) (*yaml.Node, error) {
+	var root yaml.Node
+	if isJSON(path) {
+		var err error
+		if data, err = jsonToYAML(data); err != nil {
+			return nil, err
+		}
+	}
+	if !isTOML(path) {
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, err
+		}
+		return &root, nil
+	}
+
+	var values map[string]interface{}
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	if err := root.Encode(values); err != nil {
+		return nil, err
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{&root}}, nil
+}
+
+// jsonToYAML 按 JSON 语法检查内容，并将其中 YAML 不支持的字符串转义（如 \/ 和 \u 代理对）转换为 YAML 的写法
+// 只改写包含转义的字符串，变短时在字符串之后补齐空格，保持之后内容的行号和列号不变
+func jsonToYAML(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line := bytes.Count(data[:syntaxErr.Offset], []byte("\n")) + 1
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	for i := 0; i < len(data); i++ {
+		if data[i] != '"' {
+			out.WriteByte(data[i])
+			continue
+		}
+		// 语法已检查，字符串在下一个未转义的引号处结束
+		end, escaped := i+1, false
+		for ; data[end] != '"'; end++ {
+			if data[end] == '\\' {
+				escaped = true
+				end++
+			}
+		}
+		literal := data[i : end+1]
+		i = end
+		if !escaped {
+			out.Write(literal)
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(literal, &s); err != nil {
+			return nil, err
+		}
+		quoted := strconv.Quote(s)
+		out.WriteString(quoted)
+		if pad := utf8.RuneCount(literal) - utf8.RuneCountInString(quoted); pad > 0 {
+			out.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFileFormats(t *testing.T) {
+	t.Setenv("RSS2TG_TEST_TOKEN", "env-token")
+
+	files := map[string]string{
+		"config.yaml": `
+telegram:
+  bot_token: "${RSS2TG_TEST_TOKEN}"
+  check_interval: 300
+templates:
+  short: "{title}"
+feeds:
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a"]
+    template: "@short"
+    disable_notification: true
+`,
+		"config.json": `{
+	"telegram": {"bot_token": "${RSS2TG_TEST_TOKEN}", "check_interval": 300},
+	"templates": {"short": "{title}"},
+	"feeds": [
+		{"name": "a", "url": "https://example.com/a.xml", "channels": ["@a"], "template": "@short", "disable_notification": true}
+	]
+}`,
+		"config.toml": `
+[telegram]
+bot_token = "${RSS2TG_TEST_TOKEN}"
+check_interval = 300
+
+[templates]
+short = "{title}"
+
+[[feeds]]
+name = "a"
+url = "https://example.com/a.xml"
+channels = ["@a"]
+template = "@short"
+disable_notification = true
+`,
+	}
+
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	for name := range files {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadFile(filepath.Join(dir, name), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "env-token", cfg.Telegram.BotToken)
+			assert.Equal(t, 300, cfg.Telegram.CheckInterval)
+			assert.Equal(t, "a", cfg.Feeds[0].Name)
+			assert.Equal(t, []string{"@a"}, cfg.Feeds[0].Channels)
+			assert.Equal(t, "{title}", cfg.Feeds[0].Template)
+			assert.True(t, *cfg.Feeds[0].DisableNotification)
+		})
+	}
+
+	// JSON 和 TOML 中非字符串的配置项引用环境变量时必须加引号
+	t.Setenv("RSS2TG_TEST_INT", "120")
+	t.Setenv("RSS2TG_TEST_BOOL", "true")
+	t.Setenv("RSS2TG_TEST_DIGITS", "123456")
+	quoted := map[string]string{
+		"quoted.json": `{
+	"telegram": {"bot_token": "${RSS2TG_TEST_DIGITS}", "check_interval": "${RSS2TG_TEST_INT}"},
+	"feeds": [
+		{"name": "${RSS2TG_TEST_INT}", "url": "https://example.com/a.xml", "channels": ["@a"], "disable_notification": "${RSS2TG_TEST_BOOL}"}
+	]
+}`,
+		"quoted.toml": `
+[telegram]
+bot_token = "${RSS2TG_TEST_DIGITS}"
+check_interval = "${RSS2TG_TEST_INT}"
+
+[[feeds]]
+name = "${RSS2TG_TEST_INT}"
+url = "https://example.com/a.xml"
+channels = ["@a"]
+disable_notification = "${RSS2TG_TEST_BOOL}"
+`,
+	}
+	writeFiles(t, dir, quoted)
+	for name := range quoted {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := LoadFile(filepath.Join(dir, name), nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, "123456", cfg.Telegram.BotToken)
+			assert.Equal(t, 120, cfg.Telegram.CheckInterval)
+			assert.Equal(t, "120", cfg.Feeds[0].Name)
+			assert.True(t, *cfg.Feeds[0].DisableNotification)
+		})
+	}
+}
+
+func TestCheckFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.json": `{
+  "telegram": {"bot_token": "token", "check_interval": 300},
+  "feeds": [
+    {"name": "a", "url": "https://example.com/a.xml", "channels": ["@a"], "tempalte": "{title}"},
+    {"name": "b", "url": "https://example.com/b.xml"}
+  ]
+}`,
+		"config.toml": `
+[telegram]
+bot_token = "token"
+check_interval = 300
+
+[[feeds]]
+name = "a"
+url = "https://example.com/a.xml"
+channels = ["@a"]
+tempalte = "{title}"
+
+[[feeds]]
+name = "b"
+url = "https://example.com/b.xml"
+`,
+		"broken.toml": "[telegram\n",
+	})
+
+	// JSON 报告行号，TOML 没有行号
+	_, warnings, err := Check(filepath.Join(dir, "config.json"), nil)
+	assert.Equal(t, []string{filepath.Join(dir, "config.json") + `:4: unknown field "tempalte"`}, warnings)
+	assert.EqualError(t, err, filepath.Join(dir, "config.json")+":5:5: feeds[1]: feed b must have at least one channel")
+
+	_, warnings, err = Check(filepath.Join(dir, "config.toml"), nil)
+	assert.Equal(t, []string{filepath.Join(dir, "config.toml") + `: unknown field "tempalte"`}, warnings)
+	assert.EqualError(t, err, filepath.Join(dir, "config.toml")+": feeds[1]: feed b must have at least one channel")
+
+	_, err = LoadFile(filepath.Join(dir, "broken.toml"), nil)
+	assert.ErrorContains(t, err, "broken.toml")
+}
+
+func TestJSONEscapes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.json": `{
+  "telegram": {"bot_token": "token", "check_interval": 300},
+  "feeds": [
+    {"name": "a\u0026b \ud83d\udd25", "url": "https:\/\/example.com\/a.xml", "channels": ["@a"], "template": "\u003cb\u003e{title}\u003c/b\u003e\n\"{link}\""},
+    {"name": "\u4e2d\u6587", "url": "https:\/\/example.com\/b.xml", "channels": ["@b"], "tempalte": "{title}"}, {"name": "c"}
+  ]
+}`,
+		"broken.json": "{\n  \"telegram\": {\"bot_token\": \"token\",}\n}",
+	})
+
+	path := filepath.Join(dir, "config.json")
+	_, warnings, err := Check(path, nil)
+	// 转义后的字符串变短，之后的位置不变
+	assert.Equal(t, []string{path + `:5: unknown field "tempalte"`}, warnings)
+	assert.EqualError(t, err, path+":5:113: feeds[2]: feed URL is required")
+
+	writeFiles(t, dir, map[string]string{"config.json": strings.Replace(mustRead(t, path), `, {"name": "c"}`, "", 1)})
+	cfg, err := LoadFile(path, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "a&b 🔥", cfg.Feeds[0].Name)
+	assert.Equal(t, "https://example.com/a.xml", cfg.Feeds[0].URL)
+	assert.Equal(t, "<b>{title}</b>\n\"{link}\"", cfg.Feeds[0].Template)
+	assert.Equal(t, "中文", cfg.Feeds[1].Name)
+
+	_, err = LoadFile(filepath.Join(dir, "broken.json"), nil)
+	assert.ErrorContains(t, err, "broken.json: line 2: invalid character '}'")
+}
+
+func TestSchema(t *testing.T) {
+	schema := Schema()
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Contains(t, properties, "telegram")
+	assert.NotContains(t, properties, "Source")
+
+	feed := properties["feeds"].(map[string]interface{})["items"].(map[string]interface{})
+	assert.Equal(t, false, feed["additionalProperties"])
+	feedProperties := feed["properties"].(map[string]interface{})
+	// inline 的发送选项合并到 feed 中
+	assert.Contains(t, feedProperties, "disable_notification")
+	assert.NotContains(t, feedProperties, "source")
+	// 取值有限的配置项也可以引用环境变量
+	assert.Equal(t, map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{"type": "string", "enum": []string{ParseModeMarkdown, ParseModeMarkdownV2, ParseModeHTML, ParseModeNone}},
+		envRefSchema,
+	}}, feedProperties["parse_mode"])
+	assert.Equal(t, map[string]interface{}{"type": "string"}, feedProperties["url"])
+	// 指针类型的配置项可以为 null
+	assert.Equal(t, map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{"type": "boolean"}, envRefSchema, map[string]interface{}{"type": "null"},
+	}}, feedProperties["disable_notification"])
+	quietHours := feedProperties["quiet_hours"].(map[string]interface{})["anyOf"].([]interface{})
+	if assert.Len(t, quietHours, 2) {
+		assert.Equal(t, "object", quietHours[0].(map[string]interface{})["type"])
+		assert.Equal(t, map[string]interface{}{"type": "null"}, quietHours[1])
+	}
+	assert.Equal(t, []interface{}{map[string]interface{}{"type": "integer"}, envRefSchema},
+		properties["telegram"].(map[string]interface{})["properties"].(map[string]interface{})["check_interval"].(map[string]interface{})["anyOf"])
+}
+
+func mustRead(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	return string(data)
+}
@@ -0,0 +1,337 @@
+package config
+
+//配置目录和 include
+//-config 指向目录时，按文件名顺序合并目录中所有配置文件（.yaml/.yml/.json/.toml）
+//配置文件中的 include 引入其他配置文件（支持 glob），合并其中的 feeds 和 templates
+//各文件中 template_file 等相对路径基于该文件所在目录
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BaseDir 返回配置的基准目录，path 为目录时返回 path 本身，否则返回文件所在目录
+func BaseDir(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return path
+	}
+	return filepath.Dir(path)
+}
+
+// readConfig 读取配置文件或配置目录，合并 include 引入的文件
+// 返回合并后的配置（未验证），result 中记录需要监控的文件和目录以及警告
+func readConfig(path string, result *loadResult) (*Config, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	var cfg *Config
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isConfigFile(entry.Name()) {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no config files found in %s", path)
+		}
+		cfg = &Config{}
+	} else {
+		if cfg, err = decodeFile(path, result); err != nil {
+			return nil, err
+		}
+		if files, err = expandIncludes(path, cfg.Include); err != nil {
+			return nil, err
+		}
+	}
+
+	// 监控配置文件（目录）本身、合并的每个文件，以及 include 的 glob 以发现新增的文件
+	result.watch = append(result.watch, path)
+	for _, pattern := range cfg.Include {
+		result.includes = append(result.includes, filepath.Clean(includePath(path, pattern)))
+	}
+
+	m := merger{cfg: cfg, source: path}
+	for _, file := range files {
+		part, err := decodeFile(file, result)
+		if err != nil {
+			return nil, err
+		}
+		if len(part.Include) > 0 {
+			if info.IsDir() {
+				return nil, fmt.Errorf("%s: include is not supported in config directory", file)
+			}
+			return nil, fmt.Errorf("%s: nested include is not supported", file)
+		}
+		if err := m.merge(part, file); err != nil {
+			return nil, err
+		}
+		result.watch = append(result.watch, file)
+	}
+	return cfg, nil
+}
+
+// includePath 返回 include 的绝对或相对于主配置文件的路径
+func includePath(configPath, pattern string) string {
+	if filepath.IsAbs(pattern) {
+		return pattern
+	}
+	return filepath.Join(filepath.Dir(configPath), pattern)
+}
+
+// expandIncludes 展开 include 中的路径和 glob，按文件名排序并去重
+// 不含通配符的路径不存在时返回错误，glob 没有匹配的文件时忽略
+func expandIncludes(configPath string, patterns []string) ([]string, error) {
+	seen := map[string]bool{filepath.Clean(configPath): true}
+	var files []string
+	for _, pattern := range patterns {
+		full := includePath(configPath, pattern)
+		matches, err := filepath.Glob(full)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("include %q: file not found", pattern)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			match = filepath.Clean(match)
+			if seen[match] {
+				continue
+			}
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			seen[match] = true
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// decodeFile 读取单个配置文件，替换环境变量，并将相对路径转换为基于该文件所在目录的路径
+// 不支持的配置项记录到 result 的警告中
+func decodeFile(path string, result *loadResult) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := parseConfigNode(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	// 严格解析检查不支持的配置项，TOML 转换后的节点没有行号
+	strictData := data
+	switch {
+	case isJSON(path):
+		if strictData, err = jsonToYAML(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case isTOML(path):
+		if strictData, err = yaml.Marshal(root); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	result.warnings = append(result.warnings, unknownFields(path, strictData, !isTOML(path))...)
+
+	// 替换环境变量引用
+	if err := expandEnvNode(root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	var cfg Config
+	if err := root.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	dir := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(dir, p)
+	}
+	cfg.Telegram.BotTokenFile = resolve(cfg.Telegram.BotTokenFile)
+	for name, shared := range cfg.Templates {
+		shared.File = resolve(shared.File)
+		cfg.Templates[name] = shared
+	}
+	cfg.Positions = positions(path, root)
+	feedNodes := feedNodes(root)
+	for i := range cfg.Feeds {
+		cfg.Feeds[i].TemplateFile = resolve(cfg.Feeds[i].TemplateFile)
+		cfg.Feeds[i].Source = FeedSource{File: path, Index: i}
+		if i < len(feedNodes) {
+			cfg.Feeds[i].Source.Line, cfg.Feeds[i].Source.Column = feedNodes[i].Line, feedNodes[i].Column
+		}
+	}
+	return &cfg, nil
+}
+
+// 严格解析时不支持的配置项的错误信息，例如 line 5: field foo not found in type config.FeedConfig
+var unknownFieldRegex = regexp.MustCompile(`^line (\d+): field (.+) not found in type`)
+
+// unknownFields 严格解析 YAML 内容，返回不支持的配置项，例如拼写错误的配置项名称
+// withLine 为 false 时不输出行号
+func unknownFields(path string, data []byte, withLine bool) []string {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var typeErr *yaml.TypeError
+	if err := decoder.Decode(&Config{}); !errors.As(err, &typeErr) {
+		return nil
+	}
+
+	var warnings []string
+	for _, msg := range typeErr.Errors {
+		m := unknownFieldRegex.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		if withLine {
+			warnings = append(warnings, fmt.Sprintf("%s:%s: unknown field %q", path, m[1], m[2]))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("%s: unknown field %q", path, m[2]))
+		}
+	}
+	return warnings
+}
+
+// topLevelNode 返回 YAML 文档中顶层配置项的键节点和值节点，不存在时返回 nil
+func topLevelNode(root *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 {
+		return nil, nil
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(doc.Content); i += 2 {
+		if doc.Content[i].Value == key {
+			return doc.Content[i], doc.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// feedNodes 返回 YAML 文档中 feeds 列表的各项节点
+func feedNodes(root *yaml.Node) []*yaml.Node {
+	if _, value := topLevelNode(root, "feeds"); value != nil && value.Kind == yaml.SequenceNode {
+		return value.Content
+	}
+	return nil
+}
+
+// positions 返回文件中 telegram、timezone 和各共享模板的位置（键所在的行列）
+func positions(path string, root *yaml.Node) Positions {
+	at := func(node *yaml.Node) Position {
+		return Position{File: path, Line: node.Line, Column: node.Column}
+	}
+	var p Positions
+	if key, _ := topLevelNode(root, "telegram"); key != nil {
+		p.Telegram = at(key)
+	}
+	if key, _ := topLevelNode(root, "timezone"); key != nil {
+		p.Timezone = at(key)
+	}
+	if _, value := topLevelNode(root, "templates"); value != nil && value.Kind == yaml.MappingNode {
+		p.Templates = make(map[string]Position)
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			p.Templates[value.Content[i].Value] = at(value.Content[i])
+		}
+	}
+	return p
+}
+
+// merger 合并多个配置文件，telegram、timezone、defaults 和同名共享模板只能在一个文件中定义
+type merger struct {
+	cfg    *Config
+	source string // cfg 已有内容所在的文件
+
+	telegramSource string
+	timezoneSource string
+	defaultsSource string
+	templateSource map[string]string
+}
+
+func (m *merger) merge(part *Config, file string) error {
+	if m.templateSource == nil {
+		m.templateSource = make(map[string]string)
+		if m.cfg.Telegram != (TelegramConfig{}) {
+			m.telegramSource = m.source
+		}
+		if m.cfg.Timezone != "" {
+			m.timezoneSource = m.source
+		}
+		if !reflect.ValueOf(m.cfg.Defaults).IsZero() {
+			m.defaultsSource = m.source
+		}
+		for name := range m.cfg.Templates {
+			m.templateSource[name] = m.source
+		}
+	}
+
+	if part.Telegram != (TelegramConfig{}) {
+		if m.telegramSource != "" {
+			return fmt.Errorf("telegram is defined in both %s and %s", m.telegramSource, file)
+		}
+		m.cfg.Telegram, m.telegramSource = part.Telegram, file
+		m.cfg.Positions.Telegram = part.Positions.Telegram
+	}
+	if part.Timezone != "" {
+		if m.timezoneSource != "" {
+			return fmt.Errorf("timezone is defined in both %s and %s", m.timezoneSource, file)
+		}
+		m.cfg.Timezone, m.timezoneSource = part.Timezone, file
+		m.cfg.Positions.Timezone = part.Positions.Timezone
+	}
+	if !reflect.ValueOf(part.Defaults).IsZero() {
+		if m.defaultsSource != "" {
+			return fmt.Errorf("defaults is defined in both %s and %s", m.defaultsSource, file)
+		}
+		m.cfg.Defaults, m.defaultsSource = part.Defaults, file
+	}
+	for name, shared := range part.Templates {
+		if first, ok := m.templateSource[name]; ok {
+			return fmt.Errorf("template %s is defined in both %s and %s", name, first, file)
+		}
+		if m.cfg.Templates == nil {
+			m.cfg.Templates = make(map[string]SharedTemplate)
+		}
+		if m.cfg.Positions.Templates == nil {
+			m.cfg.Positions.Templates = make(map[string]Position)
+		}
+		m.cfg.Templates[name], m.templateSource[name] = shared, file
+		m.cfg.Positions.Templates[name] = part.Positions.Templates[name]
+	}
+	m.cfg.Feeds = append(m.cfg.Feeds, part.Feeds...)
+	return nil
+}
+
+// uniquePaths 去除重复的路径，保持原顺序
+func uniquePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,258 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles 在 dir 中写入文件，自动创建子目录
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+const telegramConfig = `
+telegram:
+  bot_token: "token"
+  check_interval: 300
+`
+
+func feedNames(cfg *Config) []string {
+	var names []string
+	for _, feed := range cfg.Feeds {
+		names = append(names, feed.Name)
+	}
+	return names
+}
+
+func TestLoadConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"00-main.yaml": telegramConfig + `
+templates:
+  short: "{title}"
+`,
+		"news.yaml": `
+feeds:
+  - name: b
+    url: https://example.com/b.xml
+    channels: ["@news"]
+    template: "@short"
+`,
+		"deals.yml": `
+feeds:
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@deals"]
+    template_file: templates/deal.tmpl
+`,
+		"templates/deal.tmpl": "💰 {title}",
+		"config.yaml.example": "not: [valid",
+		"rss2telegram-data/x": "",
+	})
+
+	cfg, err := LoadFile(dir, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "token", cfg.Telegram.BotToken)
+	// 按文件名顺序合并
+	assert.Equal(t, []string{"a", "b"}, feedNames(cfg))
+	assert.Equal(t, "💰 {title}", cfg.Feeds[0].Template)
+	assert.Equal(t, "{title}", cfg.Feeds[1].Template)
+	assert.Equal(t, FeedSource{File: filepath.Join(dir, "deals.yml"), Index: 0, Line: 3, Column: 5}, cfg.Feeds[0].Source)
+	assert.Equal(t, dir, BaseDir(dir))
+}
+
+func TestLoadConfigInclude(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml": telegramConfig + `
+include:
+  - feeds/*.yaml
+  - extra.yaml
+feeds:
+  - name: main
+    url: https://example.com/main.xml
+    channels: ["@main"]
+`,
+		"feeds/b.yaml": `
+feeds:
+  - name: b
+    url: https://example.com/b.xml
+    channels: ["@b"]
+    template_file: b.tmpl
+`,
+		"feeds/a.yaml": `
+feeds:
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a"]
+`,
+		"feeds/b.tmpl": "B {title}",
+		"extra.yaml": `
+feeds:
+  - name: extra
+    url: https://example.com/extra.xml
+    channels: ["@extra"]
+`,
+	})
+
+	cfg, err := LoadFile(filepath.Join(dir, "config.yaml"), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []string{"main", "a", "b", "extra"}, feedNames(cfg))
+	// 模板文件路径基于定义该 feed 的文件所在目录
+	assert.Equal(t, "B {title}", cfg.Feeds[2].Template)
+}
+
+func TestLoadConfigIncludeErrors(t *testing.T) {
+	feed := func(name string) string {
+		return "feeds:\n  - name: " + name + "\n    url: https://example.com/" + name + ".xml\n    channels: [\"@c\"]\n"
+	}
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "跨文件的重复名称",
+			files: map[string]string{
+				"config.yaml":  telegramConfig + "include: [feeds/*.yaml]\n" + feed("a"),
+				"feeds/a.yaml": feed("a"),
+			},
+			err: "duplicate feed name found: a (in ",
+		},
+		{
+			name: "include 文件不存在",
+			files: map[string]string{
+				"config.yaml": telegramConfig + "include: [missing.yaml]\n" + feed("a"),
+			},
+			err: `include "missing.yaml": file not found`,
+		},
+		{
+			name: "嵌套 include",
+			files: map[string]string{
+				"config.yaml": telegramConfig + "include: [b.yaml]\n" + feed("a"),
+				"b.yaml":      "include: [c.yaml]\n" + feed("b"),
+			},
+			err: "nested include is not supported",
+		},
+		{
+			name: "telegram 重复定义",
+			files: map[string]string{
+				"config.yaml": telegramConfig + "include: [b.yaml]\n" + feed("a"),
+				"b.yaml":      telegramConfig,
+			},
+			err: "telegram is defined in both",
+		},
+		{
+			name: "共享模板重复定义",
+			files: map[string]string{
+				"config.yaml": telegramConfig + "include: [b.yaml]\ntemplates:\n  t: x\n" + feed("a"),
+				"b.yaml":      "templates:\n  t: y\n",
+			},
+			err: "template t is defined in both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+			_, err := LoadFile(filepath.Join(dir, "config.yaml"), nil)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestManagerReloadsConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.yaml": telegramConfig + `
+feeds:
+  - name: a
+    url: https://example.com/a.xml
+    channels: ["@a"]
+`,
+	})
+
+	m, err := NewManager(dir, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer m.Close()
+
+	reloaded := make(chan int, 16)
+	m.OnConfigChange(func(cfg *Config) {
+		select {
+		case reloaded <- len(cfg.Feeds):
+		default:
+		}
+	})
+
+	// 目录中新增的配置文件触发重新加载
+	writeFiles(t, dir, map[string]string{
+		"more.yaml": `
+feeds:
+  - name: b
+    url: https://example.com/b.xml
+    channels: ["@b"]
+`,
+	})
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case count := <-reloaded:
+			if count == 2 {
+				return
+			}
+		case <-timeout:
+			t.Fatal("config was not reloaded after adding a file to the config directory")
+		}
+	}
+}
+
+func TestShouldReloadMatchesIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"config.yaml":  "include: [feeds/*.yaml]\n" + feedConfig("a"),
+		"feeds/b.yaml": "feeds:\n  - name: b\n    url: https://example.com/b.xml\n    channels: [\"@b\"]\n",
+	})
+	m := &Manager{filepath: filepath.Join(dir, "config.yaml")}
+	if !assert.NoError(t, m.Load()) {
+		return
+	}
+
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		file string
+		want bool
+	}{
+		{name: "修改已引入的文件", op: fsnotify.Write, file: "feeds/b.yaml", want: true},
+		{name: "新增匹配的文件", op: fsnotify.Create, file: "feeds/c.yaml", want: true},
+		{name: "删除匹配的文件", op: fsnotify.Remove, file: "feeds/d.yaml", want: true},
+		{name: "扩展名不匹配", op: fsnotify.Create, file: "feeds/c.yml", want: false},
+		{name: "非配置文件", op: fsnotify.Create, file: "feeds/notes.txt", want: false},
+		{name: "主配置目录中的其他文件", op: fsnotify.Create, file: "other.yaml", want: false},
+		{name: "修改权限", op: fsnotify.Chmod, file: "feeds/c.yaml", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := fsnotify.Event{Name: filepath.Join(dir, tt.file), Op: tt.op}
+			assert.Equal(t, tt.want, m.shouldReload(event))
+		})
+	}
+}
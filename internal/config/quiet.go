@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// 免打扰时段的处理方式
+const (
+	QuietModeQueue  = "queue"  // 暂存消息，时段结束后按顺序推送（默认）
+	QuietModeSilent = "silent" // 照常推送，但不触发通知
+)
+
+// QuietHoursConfig 免打扰时段配置，start 晚于 end 时表示跨越零点，start 和 end 不能相同
+type QuietHoursConfig struct {
+	Start    string `yaml:"start"`    // 开始时间 HH:MM
+	End      string `yaml:"end"`      // 结束时间 HH:MM
+	Timezone string `yaml:"timezone"` // IANA 时区，默认本地时区
+	Mode     string `yaml:"mode"`     // queue(默认) / silent
+}
+
+// Validate 验证免打扰时段配置，未配置时返回 nil
+func (q *QuietHoursConfig) Validate() error {
+	if q == nil {
+		return nil
+	}
+	start, err := ParseClock(q.Start)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	end, err := ParseClock(q.End)
+	if err != nil {
+		return fmt.Errorf("end: %w", err)
+	}
+	// 开始和结束时间相同时无法区分全天免打扰和不免打扰
+	if start == end {
+		return fmt.Errorf("start and end must be different")
+	}
+	if _, err := LoadLocation(q.Timezone); err != nil {
+		return err
+	}
+	switch q.Mode {
+	case "", QuietModeQueue, QuietModeSilent:
+	default:
+		return fmt.Errorf("invalid mode %q", q.Mode)
+	}
+	return nil
+}
+
+// Active 判断 now 是否处于免打扰时段
+func (q *QuietHoursConfig) Active(now time.Time) bool {
+	if q == nil {
+		return false
+	}
+	start, err := ParseClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(q.End)
+	if err != nil {
+		return false
+	}
+	loc, err := LoadLocation(q.Timezone)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(loc)
+	minutes := local.Hour()*60 + local.Minute()
+	if start <= end {
+		return minutes >= start && minutes < end
+	}
+	// 跨越零点，例如 23:00 - 07:00
+	return minutes >= start || minutes < end
+}
+
+// EffectiveMode 返回处理方式，未配置时为 queue
+func (q *QuietHoursConfig) EffectiveMode() string {
+	if q.Mode == "" {
+		return QuietModeQueue
+	}
+	return q.Mode
+}
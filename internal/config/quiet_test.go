@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuietHoursActive(t *testing.T) {
+	day := func(hour, minute int) time.Time {
+		return time.Date(2024, 12, 10, hour, minute, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		quiet *QuietHoursConfig
+		now   time.Time
+		want  bool
+	}{
+		{"未配置", nil, day(12, 0), false},
+		{"当天时段内", &QuietHoursConfig{Start: "12:00", End: "14:00", Timezone: "UTC"}, day(12, 0), true},
+		{"当天时段结束", &QuietHoursConfig{Start: "12:00", End: "14:00", Timezone: "UTC"}, day(14, 0), false},
+		{"当天时段前", &QuietHoursConfig{Start: "12:00", End: "14:00", Timezone: "UTC"}, day(11, 59), false},
+		{"跨零点的开始", &QuietHoursConfig{Start: "23:00", End: "07:00", Timezone: "UTC"}, day(23, 30), true},
+		{"跨零点的零点后", &QuietHoursConfig{Start: "23:00", End: "07:00", Timezone: "UTC"}, day(6, 59), true},
+		{"跨零点的时段外", &QuietHoursConfig{Start: "23:00", End: "07:00", Timezone: "UTC"}, day(7, 0), false},
+		{"使用配置的时区", &QuietHoursConfig{Start: "23:00", End: "07:00", Timezone: "Asia/Shanghai"}, day(16, 0), true},   // 上海 00:00
+		{"时区之外的时间", &QuietHoursConfig{Start: "23:00", End: "07:00", Timezone: "Asia/Shanghai"}, day(23, 30), false}, // 上海 07:30
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.quiet.Active(tt.now))
+		})
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		quiet   *QuietHoursConfig
+		wantErr string
+	}{
+		{"未配置", nil, ""},
+		{"跨零点", &QuietHoursConfig{Start: "23:00", End: "07:00", Mode: QuietModeSilent}, ""},
+		{"开始和结束相同", &QuietHoursConfig{Start: "08:00", End: "08:00"}, "must be different"},
+		{"无效的时间", &QuietHoursConfig{Start: "8", End: "09:00"}, "start"},
+		{"无效的时区", &QuietHoursConfig{Start: "23:00", End: "07:00", Timezone: "Mars/Base"}, "invalid timezone"},
+		{"无效的处理方式", &QuietHoursConfig{Start: "23:00", End: "07:00", Mode: "drop"}, "invalid mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.quiet.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 定时推送计划
+// 支持三种格式：
+//   - "every 3h": 固定间隔
+//   - "daily 09:00" 或 "daily 09:00,18:00": 每天的固定时间
+//   - "weekly mon 09:00" 或 "weekly mon,thu 09:00,18:00": 每周指定几天的固定时间
+type Schedule struct {
+	Interval time.Duration  // every 模式的间隔
+	Weekdays []time.Weekday // weekly 模式下推送的星期（升序），为空时每天推送
+	Times    []int          // daily 和 weekly 模式下当天的推送时间（从0点开始的分钟数，升序）
+	Location *time.Location // daily 和 weekly 模式使用的时区
+}
+
+// 星期的缩写
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+const scheduleUsage = "expected \"every <duration>\", \"daily <HH:MM>\" or \"weekly <days> <HH:MM>\""
+
+// ParseSchedule 解析推送计划，timezone 为空时使用本地时区
+func ParseSchedule(spec string, timezone string) (*Schedule, error) {
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) == 3 && fields[0] == "weekly" {
+		weekdays, err := parseWeekdays(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		schedule, err := ParseSchedule("daily "+fields[2], timezone)
+		if err != nil {
+			return nil, err
+		}
+		schedule.Weekdays = weekdays
+		return schedule, nil
+	}
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid schedule %q, %s", spec, scheduleUsage)
+	}
+
+	schedule := &Schedule{Location: loc}
+	switch fields[0] {
+	case "every":
+		interval, err := time.ParseDuration(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule interval %q: %w", fields[1], err)
+		}
+		if interval < time.Minute {
+			return nil, fmt.Errorf("schedule interval %s is too short", interval)
+		}
+		schedule.Interval = interval
+	case "daily":
+		for _, clock := range strings.Split(fields[1], ",") {
+			minutes, err := ParseClock(clock)
+			if err != nil {
+				return nil, err
+			}
+			schedule.Times = append(schedule.Times, minutes)
+		}
+		sort.Ints(schedule.Times)
+	default:
+		return nil, fmt.Errorf("invalid schedule %q, %s", spec, scheduleUsage)
+	}
+	return schedule, nil
+}
+
+// parseWeekdays 解析逗号分隔的星期缩写，如 "mon,thu"
+func parseWeekdays(spec string) ([]time.Weekday, error) {
+	var weekdays []time.Weekday
+	for _, name := range strings.Split(spec, ",") {
+		weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q, expected sun, mon, tue, wed, thu, fri or sat", name)
+		}
+		weekdays = append(weekdays, weekday)
+	}
+	sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+	return weekdays, nil
+}
+
+// Next 返回 since 之后的下一次推送时间
+func (s *Schedule) Next(since time.Time) time.Time {
+	if s.Interval > 0 {
+		return since.Add(s.Interval)
+	}
+
+	local := since.In(s.Location)
+	for day := 0; ; day++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, s.Location)
+		if !s.onWeekday(date.Weekday()) {
+			continue
+		}
+		for _, minutes := range s.Times {
+			next := time.Date(date.Year(), date.Month(), date.Day(), minutes/60, minutes%60, 0, 0, s.Location)
+			if next.Hour()*60+next.Minute() != minutes {
+				// 夏令时开始时跳过的时间，顺延跳过的时长，例如 02:30 推迟到 03:30
+				_, before := next.Zone()
+				_, after := next.Add(12 * time.Hour).Zone()
+				next = next.Add(time.Duration(after-before) * time.Second)
+			}
+			if next.After(since) {
+				return next
+			}
+		}
+	}
+}
+
+// onWeekday 判断计划是否在指定的星期推送
+func (s *Schedule) onWeekday(weekday time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, w := range s.Weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClock 解析 HH:MM 格式的时间，返回从0点开始的分钟数
+func ParseClock(clock string) (int, error) {
+	parts := strings.Split(strings.TrimSpace(clock), ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", clock)
+	}
+	hour, err := strconv.Atoi(parts[0])
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", clock)
+	}
+	minute, err := strconv.Atoi(parts[1])
+	if err != nil || minute < 0 || minute > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", clock)
+	}
+	return hour*60 + minute, nil
+}
+
+// LoadLocation 加载 IANA 时区，为空时使用本地时区
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", timezone, err)
+	}
+	return loc, nil
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		spec     string
+		timezone string
+		want     *Schedule
+		wantErr  string
+	}{
+		{spec: "every 3h", want: &Schedule{Interval: 3 * time.Hour, Location: time.Local}},
+		{spec: "daily 18:00,09:30", timezone: "UTC", want: &Schedule{Times: []int{570, 1080}, Location: time.UTC}},
+		{spec: "weekly mon 09:00", timezone: "UTC", want: &Schedule{Weekdays: []time.Weekday{time.Monday}, Times: []int{540}, Location: time.UTC}},
+		{spec: "weekly sat,sun 10:00", timezone: "UTC", want: &Schedule{Weekdays: []time.Weekday{time.Sunday, time.Saturday}, Times: []int{600}, Location: time.UTC}},
+		{spec: "every 30s", wantErr: "too short"},
+		{spec: "every soon", wantErr: "invalid schedule interval"},
+		{spec: "daily 24:00", wantErr: "invalid hour"},
+		{spec: "daily 9", wantErr: "expected HH:MM"},
+		{spec: "weekly 09:00", wantErr: "invalid schedule"},
+		{spec: "weekly someday 09:00", wantErr: "invalid weekday"},
+		{spec: "hourly", wantErr: "invalid schedule"},
+		{spec: "daily 09:00", timezone: "Mars/Base", wantErr: "invalid timezone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseSchedule(tt.spec, tt.timezone)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		spec  string
+		tz    string
+		since time.Time
+		want  time.Time
+	}{
+		{
+			name:  "固定间隔",
+			spec:  "every 3h",
+			since: time.Date(2024, 12, 10, 23, 0, 0, 0, time.UTC),
+			want:  time.Date(2024, 12, 11, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "当天的下一个时间",
+			spec:  "daily 09:00,18:00",
+			tz:    "Asia/Shanghai",
+			since: time.Date(2024, 12, 10, 9, 0, 0, 0, shanghai),
+			want:  time.Date(2024, 12, 10, 18, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "跨天",
+			spec:  "daily 09:00,18:00",
+			tz:    "Asia/Shanghai",
+			since: time.Date(2024, 12, 10, 18, 30, 0, 0, shanghai),
+			want:  time.Date(2024, 12, 11, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "跨月跨年",
+			spec:  "daily 09:00",
+			tz:    "Asia/Shanghai",
+			since: time.Date(2024, 12, 31, 10, 0, 0, 0, shanghai),
+			want:  time.Date(2025, 1, 1, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "since 使用其他时区",
+			spec:  "daily 09:00",
+			tz:    "Asia/Shanghai",
+			since: time.Date(2024, 12, 10, 0, 30, 0, 0, time.UTC), // 上海 08:30
+			want:  time.Date(2024, 12, 10, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "每周的下一天",
+			spec:  "weekly mon,thu 09:00",
+			tz:    "Asia/Shanghai",
+			since: time.Date(2024, 12, 10, 12, 0, 0, 0, shanghai), // 周二
+			want:  time.Date(2024, 12, 12, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "每周跨周",
+			spec:  "weekly mon 09:00",
+			tz:    "Asia/Shanghai",
+			since: time.Date(2024, 12, 9, 9, 0, 0, 0, shanghai), // 周一推送之后
+			want:  time.Date(2024, 12, 16, 9, 0, 0, 0, shanghai),
+		},
+		{
+			name:  "夏令时开始当天",
+			spec:  "daily 09:00",
+			tz:    "America/New_York",
+			since: time.Date(2024, 3, 9, 10, 0, 0, 0, newYork),
+			want:  time.Date(2024, 3, 10, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:  "夏令时跳过的时间",
+			spec:  "daily 02:30",
+			tz:    "America/New_York",
+			since: time.Date(2024, 3, 9, 3, 0, 0, 0, newYork),
+			want:  time.Date(2024, 3, 10, 3, 30, 0, 0, newYork), // 02:30 不存在，顺延 1 小时
+		},
+		{
+			name:  "夏令时结束当天",
+			spec:  "daily 09:00",
+			tz:    "America/New_York",
+			since: time.Date(2024, 11, 2, 10, 0, 0, 0, newYork),
+			want:  time.Date(2024, 11, 3, 9, 0, 0, 0, newYork),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec, tt.tz)
+			assert.NoError(t, err)
+			got := schedule.Next(tt.since)
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
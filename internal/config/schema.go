@@ -0,0 +1,110 @@
+package config
+
+//配置的 JSON Schema
+//根据 Config 的结构和 yaml 标签生成，供编辑器检查和补全配置文件
+
+import (
+	"reflect"
+	"strings"
+)
+
+// 取值有限的配置项，按配置项名称
+var schemaEnums = map[string][]string{
+	"template_engine": {TemplateEngineBrace, TemplateEngineGo},
+	"parse_mode":      {ParseModeMarkdown, ParseModeMarkdownV2, ParseModeHTML, ParseModeNone},
+	"delivery":        {DeliveryInstant, DeliveryDigest},
+	"on_update":       {OnUpdateIgnore, OnUpdateEdit, OnUpdateReply},
+	"on_removed":      {OnRemovedNone, OnRemovedDelete, OnRemovedExpire},
+	"mode":            {QuietModeQueue, QuietModeSilent},
+}
+
+// 非字符串和取值有限的配置项也可以使用 ${NAME} 引用环境变量
+var envRefSchema = map[string]interface{}{
+	"type":    "string",
+	"pattern": `^\$\{[A-Za-z_][A-Za-z0-9_]*(:-[^}]*)?\}$`,
+}
+
+// Schema 返回配置文件的 JSON Schema（draft 2020-12）
+func Schema() map[string]interface{} {
+	schema := typeSchema(reflect.TypeOf(Config{}))
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "rss2telegram config"
+	return schema
+}
+
+// typeSchema 生成类型对应的 JSON Schema
+func typeSchema(t reflect.Type) map[string]interface{} {
+	// 共享模板可以直接使用字符串
+	if t == reflect.TypeOf(SharedTemplate{}) {
+		return map[string]interface{}{
+			"anyOf": []interface{}{map[string]interface{}{"type": "string"}, structSchema(t)},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		// 指针类型的配置项可以为 null，表示未设置
+		return nullable(typeSchema(t.Elem()))
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "boolean"}, envRefSchema}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "integer"}, envRefSchema}}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t)
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema 按 yaml 标签生成对象的 JSON Schema，inline 的字段合并到当前对象，不允许未定义的配置项
+func structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "-" {
+				continue
+			}
+			if opts == "inline" {
+				addFields(field.Type)
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			schema := typeSchema(field.Type)
+			if values, ok := schemaEnums[name]; ok && field.Type.Kind() == reflect.String {
+				schema = map[string]interface{}{
+					"anyOf": []interface{}{map[string]interface{}{"type": "string", "enum": values}, envRefSchema},
+				}
+			}
+			properties[name] = schema
+		}
+	}
+	addFields(t)
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// nullable 在 schema 的可选类型中加入 null
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	null := map[string]interface{}{"type": "null"}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		return map[string]interface{}{"anyOf": append(append([]interface{}(nil), anyOf...), null)}
+	}
+	return map[string]interface{}{"anyOf": []interface{}{schema, null}}
+}
@@ -0,0 +1,129 @@
+package config
+
+//共享模板和模板文件
+//templates 中定义的模板可在 feed 中通过 template: "@name" 引用，模板内容也可以从文件加载
+//模板中的 {var:name} 在加载配置时替换为变量值，feed 的 vars 覆盖共享模板的 vars
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SharedTemplate 共享模板配置
+type SharedTemplate struct {
+	Template string `yaml:"template"`
+	// 模板文件路径，相对路径基于配置文件所在目录，与 template 二选一
+	File string `yaml:"file"`
+	// 模板变量的默认值
+	Vars map[string]string `yaml:"vars"`
+}
+
+// UnmarshalYAML 支持直接使用字符串作为模板内容
+func (t *SharedTemplate) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&t.Template)
+	}
+	type plain SharedTemplate
+	return value.Decode((*plain)(t))
+}
+
+var (
+	// 引用共享模板，例如 @news
+	templateRefRegex = regexp.MustCompile(`^@([\w.-]+)$`)
+	// 模板变量，例如 {var:footer}
+	templateVarRegex = regexp.MustCompile(`\{var:([^{}|]*)\}`)
+)
+
+// ResolveTemplates 加载 feed 引用的共享模板和模板文件，并替换模板变量
+// baseDir 为模板文件相对路径的基准目录，返回使用到的模板文件
+func (c *Config) ResolveTemplates(baseDir string) ([]string, error) {
+	var files []string
+	contents := make(map[string]string)
+	readFile := func(name string) (string, error) {
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		path = filepath.Clean(path)
+		if content, ok := contents[path]; ok {
+			return content, nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		contents[path] = string(data)
+		files = append(files, path)
+		return string(data), nil
+	}
+
+	for name, shared := range c.Templates {
+		if shared.Template != "" && shared.File != "" {
+			return nil, positionError(c.Positions.Templates[name], fmt.Errorf("template %s: template and file are mutually exclusive", name))
+		}
+	}
+
+	// 使用索引修改，range 的副本修改不会保存
+	for i := range c.Feeds {
+		feed := &c.Feeds[i]
+		text := feed.Template
+		vars := make(map[string]string)
+
+		if feed.TemplateFile != "" {
+			if feed.Template != "" {
+				return nil, fmt.Errorf("feed %s: template and template_file are mutually exclusive", feed.Name)
+			}
+			content, err := readFile(feed.TemplateFile)
+			if err != nil {
+				return nil, fmt.Errorf("feed %s: template_file: %w", feed.Name, err)
+			}
+			text = content
+		} else if m := templateRefRegex.FindStringSubmatch(strings.TrimSpace(text)); m != nil {
+			shared, ok := c.Templates[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("feed %s: undefined template %q", feed.Name, m[1])
+			}
+			text = shared.Template
+			if shared.File != "" {
+				content, err := readFile(shared.File)
+				if err != nil {
+					return nil, fmt.Errorf("feed %s: template %s: %w", feed.Name, m[1], err)
+				}
+				text = content
+			}
+			for k, v := range shared.Vars {
+				vars[k] = v
+			}
+		}
+
+		for k, v := range feed.Vars {
+			vars[k] = v
+		}
+		expanded, err := expandTemplateVars(text, vars)
+		if err != nil {
+			return nil, fmt.Errorf("feed %s: %w", feed.Name, err)
+		}
+		feed.Template = expanded
+	}
+
+	return files, nil
+}
+
+// expandTemplateVars 替换模板中的 {var:name}，变量未定义时返回错误
+func expandTemplateVars(text string, vars map[string]string) (string, error) {
+	var err error
+	result := templateVarRegex.ReplaceAllStringFunc(text, func(match string) string {
+		name := strings.TrimSpace(templateVarRegex.FindStringSubmatch(match)[1])
+		value, ok := vars[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("undefined template variable %q", name)
+		}
+		return value
+	})
+	return result, err
+}
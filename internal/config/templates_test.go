@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveTemplates(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "deal.tmpl"), []byte("💰 {title}\n{var:footer}"), 0644))
+
+	data := `
+templates:
+  news:
+    template: "📰 *{title}*\n{description|truncate:{var:length}}\n{var:footer}"
+    vars:
+      length: "200"
+      footer: "🔗 [阅读原文]({link})"
+  short: "{title}"
+  deal:
+    file: templates/deal.tmpl
+    vars:
+      footer: "#优惠"
+feeds:
+  - name: a
+    template: "@news"
+  - name: b
+    template: "@news"
+    vars:
+      footer: "来源：B"
+  - name: c
+    template: " @short "
+  - name: d
+    template_file: templates/deal.tmpl
+    vars:
+      footer: "#D"
+  - name: e
+    template: "@deal"
+  - name: f
+    template: "@me {title}"
+`
+	var cfg Config
+	assert.NoError(t, yaml.Unmarshal([]byte(data), &cfg))
+
+	files, err := cfg.ResolveTemplates(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "templates", "deal.tmpl")}, files)
+
+	expected := []string{
+		"📰 *{title}*\n{description|truncate:200}\n🔗 [阅读原文]({link})",
+		"📰 *{title}*\n{description|truncate:200}\n来源：B",
+		"{title}",
+		"💰 {title}\n#D",
+		"💰 {title}\n#优惠",
+		"@me {title}",
+	}
+	for i, feed := range cfg.Feeds {
+		assert.Equal(t, expected[i], feed.Template, feed.Name)
+	}
+}
+
+func TestResolveTemplatesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		err  string
+	}{
+		{
+			name: "Undefined template",
+			cfg:  Config{Feeds: []FeedConfig{{Name: "a", Template: "@news"}}},
+			err:  `feed a: undefined template "news"`,
+		},
+		{
+			name: "Undefined variable",
+			cfg:  Config{Feeds: []FeedConfig{{Name: "a", Template: "{title} {var:footer}"}}},
+			err:  `feed a: undefined template variable "footer"`,
+		},
+		{
+			name: "Template and template_file",
+			cfg:  Config{Feeds: []FeedConfig{{Name: "a", Template: "{title}", TemplateFile: "a.tmpl"}}},
+			err:  "feed a: template and template_file are mutually exclusive",
+		},
+		{
+			name: "Missing template file",
+			cfg:  Config{Feeds: []FeedConfig{{Name: "a", TemplateFile: "missing.tmpl"}}},
+			err:  "feed a: template_file: open",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cfg.ResolveTemplates(t.TempDir())
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+func TestManagerReloadsTemplateFile(t *testing.T) {
+	dir := t.TempDir()
+	tmpl := filepath.Join(dir, "news.tmpl")
+	assert.NoError(t, os.WriteFile(tmpl, []byte("{title}"), 0644))
+	configPath := filepath.Join(dir, "config.yaml")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`
+telegram:
+  bot_token: "token"
+  check_interval: 300
+feeds:
+  - name: a
+    url: https://example.com/rss.xml
+    channels: ["@a"]
+    template_file: news.tmpl
+`), 0644))
+
+	m, err := NewManager(configPath, nil)
+	assert.NoError(t, err)
+	defer m.Close()
+	assert.Equal(t, "{title}", m.Get().Feeds[0].Template)
+
+	// 写入文件可能触发多次事件，等待加载到新的模板内容
+	reloaded := make(chan string, 16)
+	m.OnConfigChange(func(cfg *Config) {
+		select {
+		case reloaded <- cfg.Feeds[0].Template:
+		default:
+		}
+	})
+	assert.NoError(t, os.WriteFile(tmpl, []byte("{title}\n{link}"), 0644))
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case template := <-reloaded:
+			if template == "{title}\n{link}" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("config was not reloaded after template file change")
+		}
+	}
+}
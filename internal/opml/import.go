@@ -0,0 +1,70 @@
+package opml
+
+//将 OPML 中的订阅转换为推送配置
+//按文件夹分配频道，同一订阅出现在多个文件夹中时合并频道
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Subscription 导入的订阅及其推送频道
+type Subscription struct {
+	Name     string
+	URL      string
+	Channels []string
+}
+
+// Subscriptions 按文档顺序返回导入的订阅
+// folders 为文件夹到频道的映射，没有映射的文件夹使用 defaultChannel，都没有时返回错误；
+// 同一地址出现在多个文件夹中时合并为一个订阅，频道按出现顺序去重；
+// 同名订阅从 2 开始添加序号，序号跳过文档中已有的标题，保证名称唯一
+func (o *OPML) Subscriptions(folders map[string]string, defaultChannel string) ([]Subscription, error) {
+	var subs []Subscription
+	index := make(map[string]int) // 订阅地址对应的位置
+	titles := make(map[string]bool)
+	for _, feed := range o.Feeds() {
+		channel := folders[feed.Folder]
+		if channel == "" {
+			channel = defaultChannel
+		}
+		if channel == "" {
+			return nil, fmt.Errorf("no channel for feed %q in folder %q", feed.Title, feed.Folder)
+		}
+
+		i, ok := index[feed.URL]
+		if !ok {
+			index[feed.URL] = len(subs)
+			subs = append(subs, Subscription{Name: feed.Title, URL: feed.URL, Channels: []string{channel}})
+			titles[feed.Title] = true
+			continue
+		}
+		if !contains(subs[i].Channels, channel) {
+			subs[i].Channels = append(subs[i].Channels, channel)
+		}
+	}
+
+	used := make(map[string]bool)
+	for i := range subs {
+		name := subs[i].Name
+		if used[name] {
+			// 序号不能与其他订阅的原标题相同
+			base := name
+			for n := 2; used[name] || titles[name]; n++ {
+				name = base + " " + strconv.Itoa(n)
+			}
+			subs[i].Name = name
+		}
+		used[name] = true
+	}
+	return subs, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
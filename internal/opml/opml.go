@@ -0,0 +1,105 @@
+package opml
+
+//OPML 订阅列表的读取和生成
+//阅读器通常使用文件夹（嵌套的 outline）对订阅分组
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OPML 文档
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+// Head 文档头
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// Body 文档内容
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline 订阅或文件夹，有 xmlUrl 的为订阅，否则为文件夹
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed OPML 中的一个订阅
+type Feed struct {
+	Title string
+	URL   string
+	// 所在的文件夹，多级文件夹使用 / 连接，不在文件夹中时为空
+	Folder string
+}
+
+// Parse 读取 OPML 文档
+func Parse(r io.Reader) (*OPML, error) {
+	var doc OPML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error parsing OPML: %w", err)
+	}
+	return &doc, nil
+}
+
+// Feeds 按文档顺序返回所有订阅
+// 标题依次使用 title、text 属性，都为空时使用订阅地址
+func (o *OPML) Feeds() []Feed {
+	var feeds []Feed
+	var walk func(outlines []Outline, folder string)
+	walk = func(outlines []Outline, folder string) {
+		for _, outline := range outlines {
+			if outline.XMLURL != "" {
+				feeds = append(feeds, Feed{Title: outline.title(), URL: strings.TrimSpace(outline.XMLURL), Folder: folder})
+				continue
+			}
+			sub := outline.title()
+			if folder != "" {
+				sub = folder + "/" + sub
+			}
+			walk(outline.Outlines, sub)
+		}
+	}
+	walk(o.Body.Outlines, "")
+	return feeds
+}
+
+func (o Outline) title() string {
+	for _, s := range []string{o.Title, o.Text, o.XMLURL} {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// Write 写入 OPML 文档，包含 XML 声明并缩进
+func Write(w io.Writer, doc *OPML) error {
+	if doc.Version == "" {
+		doc.Version = "2.0"
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,127 @@
+package opml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeds(t *testing.T) {
+	doc, err := Parse(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<opml version="1.0">
+  <head><title>订阅</title></head>
+  <body>
+    <outline text="科技" title="科技">
+      <outline type="rss" text="少数派" title="少数派" xmlUrl="https://sspai.com/feed"/>
+      <outline text="开发">
+        <outline type="rss" text="Go Blog" xmlUrl=" https://go.dev/blog/feed.atom "/>
+      </outline>
+    </outline>
+    <outline type="rss" xmlUrl="https://example.com/rss.xml"/>
+  </body>
+</opml>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []Feed{
+		{Title: "少数派", URL: "https://sspai.com/feed", Folder: "科技"},
+		{Title: "Go Blog", URL: "https://go.dev/blog/feed.atom", Folder: "科技/开发"},
+		{Title: "https://example.com/rss.xml", URL: "https://example.com/rss.xml"},
+	}, doc.Feeds())
+}
+
+func TestParseError(t *testing.T) {
+	_, err := Parse(strings.NewReader("<opml><body>"))
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	doc := &OPML{
+		Head: Head{Title: "rss2telegram"},
+		Body: Body{Outlines: []Outline{
+			{Text: "@news", Outlines: []Outline{{Text: "示例", Type: "rss", XMLURL: "https://example.com/rss.xml?a=1&b=2"}}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, Write(&buf, doc))
+	assert.True(t, strings.HasPrefix(buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`))
+	assert.Contains(t, buf.String(), `<opml version="2.0">`)
+
+	// 写入的内容可以重新读取
+	parsed, err := Parse(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, []Feed{{Title: "示例", URL: "https://example.com/rss.xml?a=1&b=2", Folder: "@news"}}, parsed.Feeds())
+}
+
+func TestSubscriptions(t *testing.T) {
+	doc, err := Parse(strings.NewReader(`<opml version="2.0"><body>
+  <outline text="科技">
+    <outline type="rss" text="少数派" xmlUrl="https://sspai.com/feed"/>
+    <outline type="rss" text="新闻" xmlUrl="https://example.com/a"/>
+  </outline>
+  <outline text="阅读">
+    <outline type="rss" text="少数派" xmlUrl="https://sspai.com/feed"/>
+    <outline type="rss" text="新闻" xmlUrl="https://example.com/b"/>
+  </outline>
+  <outline type="rss" text="新闻 2" xmlUrl="https://example.com/c"/>
+  <outline type="rss" text="新闻" xmlUrl="https://example.com/d"/>
+</body></opml>`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	tests := []struct {
+		name           string
+		folders        map[string]string
+		defaultChannel string
+		expected       []Subscription
+		wantErr        string
+	}{
+		{
+			name:           "多个文件夹中的订阅合并频道",
+			folders:        map[string]string{"科技": "@tech", "阅读": "@read"},
+			defaultChannel: "@news",
+			expected: []Subscription{
+				{Name: "少数派", URL: "https://sspai.com/feed", Channels: []string{"@tech", "@read"}},
+				{Name: "新闻", URL: "https://example.com/a", Channels: []string{"@tech"}},
+				{Name: "新闻 3", URL: "https://example.com/b", Channels: []string{"@read"}},
+				{Name: "新闻 2", URL: "https://example.com/c", Channels: []string{"@news"}},
+				{Name: "新闻 4", URL: "https://example.com/d", Channels: []string{"@news"}},
+			},
+		},
+		{
+			name:           "同一频道不重复",
+			defaultChannel: "@news",
+			expected: []Subscription{
+				{Name: "少数派", URL: "https://sspai.com/feed", Channels: []string{"@news"}},
+				{Name: "新闻", URL: "https://example.com/a", Channels: []string{"@news"}},
+				{Name: "新闻 3", URL: "https://example.com/b", Channels: []string{"@news"}},
+				{Name: "新闻 2", URL: "https://example.com/c", Channels: []string{"@news"}},
+				{Name: "新闻 4", URL: "https://example.com/d", Channels: []string{"@news"}},
+			},
+		},
+		{
+			name:    "没有频道",
+			folders: map[string]string{"科技": "@tech"},
+			wantErr: `no channel for feed "少数派" in folder "阅读"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs, err := doc.Subscriptions(tt.folders, tt.defaultChannel)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, subs)
+		})
+	}
+}
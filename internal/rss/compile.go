@@ -0,0 +1,254 @@
+package rss
+
+//预编译的消息模板
+//同一配置版本中每个模板只解析一次（字段、操作链、正则表达式），在文章和频道之间复用
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	md "github.com/JohannesKaufmann/html-to-markdown"
+)
+
+// 模板字段语法
+var (
+	spacedFieldRegex  = regexp.MustCompile(`{ (.*?) }`) //支持正则中使用花括号
+	compactFieldRegex = regexp.MustCompile(`{([^}]+)}`) //正则中不使用花括号的情况
+)
+
+// messageTemplate 预编译的消息模板
+type messageTemplate interface {
+	render(ctx *itemContext) string
+}
+
+// templateFunc 函数形式的消息模板
+type templateFunc func(ctx *itemContext) string
+
+func (f templateFunc) render(ctx *itemContext) string {
+	return f(ctx)
+}
+
+// templateKey 预编译模板的缓存键
+type templateKey struct {
+	engine string
+	text   string
+}
+
+// templateFor 获取预编译的模板，配置更新时清空缓存
+func (h *RssHandler) templateFor(engine, text string) messageTemplate {
+	if engine != config.TemplateEngineGo {
+		engine = config.TemplateEngineBrace
+	}
+	key := templateKey{engine: engine, text: text}
+
+	h.templateMu.Lock()
+	defer h.templateMu.Unlock()
+	if tpl, ok := h.templates[key]; ok {
+		return tpl
+	}
+
+	var tpl messageTemplate
+	if engine == config.TemplateEngineGo {
+		compiled, err := compileGoTemplate(text)
+		if err != nil {
+			log.Printf("Error parsing template: %v", err)
+			tpl = templateFunc(func(*itemContext) string { return "" })
+		} else {
+			tpl = compiled
+		}
+	} else {
+		// 非严格模式不会返回错误
+		tpl, _ = compileBraceTemplate(text, false)
+	}
+
+	if h.templates == nil {
+		h.templates = make(map[templateKey]messageTemplate)
+	}
+	h.templates[key] = tpl
+	return tpl
+}
+
+// braceTemplate 预编译的花括号语法模板
+type braceTemplate struct {
+	nodes     []*braceNode
+	converter *md.Converter
+}
+
+// braceNode 模板节点：文本、字段或条件区块
+type braceNode struct {
+	text     string
+	field    *braceField // 字段，或条件区块的条件字段
+	block    bool        // 条件区块
+	negate   bool        // {!field} 字段为空时保留
+	children []*braceNode
+}
+
+// braceField 模板中已解析的字段
+type braceField struct {
+	match string // 原始文本，不支持的字段原样输出
+	name  string
+	steps []operationStep
+}
+
+// templateSpan 模板中的字段或条件标签
+type templateSpan struct {
+	start, end int
+	field      string
+	kind       byte // 字段为 0，条件标签为 ? ! /
+}
+
+// compileBraceTemplate 解析花括号语法模板
+// strict 为 true 时不匹配的条件区块、不支持的字段和操作符、无效的操作参数返回错误，
+// 否则与原有行为一致：不匹配的条件标签和不支持的字段原样输出，不支持的操作符被忽略
+func compileBraceTemplate(text string, strict bool) (*braceTemplate, error) {
+	processor := NewTemplateProcessor()
+
+	// 已找到的部分替换为空格，保持位置不变
+	masked := []byte(text)
+	used := make([]bool, len(text))
+	var spans []templateSpan
+	addSpan := func(span templateSpan) {
+		for i := span.start; i < span.end; i++ {
+			if used[i] {
+				return // 与已找到的部分重叠，按普通文本处理
+			}
+		}
+		for i := span.start; i < span.end; i++ {
+			masked[i], used[i] = ' ', true
+		}
+		spans = append(spans, span)
+	}
+
+	tags := findConditionTags(text)
+	if err := checkConditionTags(text, tags); err != nil {
+		if strict {
+			return nil, err
+		}
+		log.Printf("Invalid conditional block in template: %v", err)
+		tags = nil
+	}
+	for _, tag := range tags {
+		addSpan(templateSpan{start: tag.start, end: tag.end, field: tag.field, kind: tag.kind})
+	}
+	for _, re := range []*regexp.Regexp{spacedFieldRegex, compactFieldRegex} {
+		for _, m := range re.FindAllSubmatchIndex(masked, -1) {
+			addSpan(templateSpan{start: m[0], end: m[1], field: string(masked[m[2]:m[3]])})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	root := &braceNode{block: true}
+	stack := []*braceNode{root}
+	last := 0
+	for _, span := range spans {
+		top := stack[len(stack)-1]
+		if span.start > last {
+			top.children = append(top.children, &braceNode{text: text[last:span.start]})
+		}
+		last = span.end
+
+		if span.kind == '/' {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		field, err := compileField(processor, span.field, text[span.start:span.end], strict)
+		if err != nil {
+			return nil, newTemplateError(text, span.start, "%v", err)
+		}
+		node := &braceNode{field: field}
+		top.children = append(top.children, node)
+		if span.kind != 0 {
+			node.block, node.negate = true, span.kind == '!'
+			stack = append(stack, node)
+		}
+	}
+	if last < len(text) {
+		root.children = append(root.children, &braceNode{text: text[last:]})
+	}
+
+	return &braceTemplate{nodes: root.children, converter: newMarkdownConverter()}, nil
+}
+
+// compileField 解析字段名称和操作链
+func compileField(processor *TemplateProcessor, field, match string, strict bool) (*braceField, error) {
+	name := strings.SplitN(field, "|", 2)[0]
+	if strict && !knownField(name) {
+		return nil, fmt.Errorf("unknown field %q", name)
+	}
+	steps, err := processor.compileOperations(field, strict)
+	if err != nil {
+		return nil, fmt.Errorf("%v in {%s}", err, field)
+	}
+	return &braceField{match: match, name: name, steps: steps}, nil
+}
+
+func (t *braceTemplate) render(ctx *itemContext) string {
+	var b strings.Builder
+	t.renderNodes(&b, ctx, t.nodes)
+	return cleanMessage(b.String())
+}
+
+func (t *braceTemplate) renderNodes(b *strings.Builder, ctx *itemContext, nodes []*braceNode) {
+	for _, node := range nodes {
+		switch {
+		case node.block:
+			// 字段结果为空时移除区块，不支持的字段视为空
+			value, ok := t.value(ctx, node.field)
+			if (ok && strings.TrimSpace(value) != "") != node.negate {
+				t.renderNodes(b, ctx, node.children)
+			}
+		case node.field != nil:
+			value, ok := t.value(ctx, node.field)
+			if !ok {
+				value = node.field.match
+			}
+			b.WriteString(value)
+		default:
+			b.WriteString(node.text)
+		}
+	}
+}
+
+// value 获取字段经过操作链处理后的内容，不支持的字段返回 false
+// 除 description/content 外的字段在操作链之后按解析模式转义
+func (t *braceTemplate) value(ctx *itemContext, field *braceField) (string, bool) {
+	steps := field.steps
+	if !legacyMarkdown(ctx.parseMode) {
+		steps = withoutEscapeMarkdown(steps)
+	}
+
+	// 时间字段直接将时间值传给操作链
+	if tv, isTime := resolveTimeField(ctx, field.name); isTime && tv != nil {
+		return escapeText(ctx.parseMode, applyTimeOperations(steps, *tv, defaultTimeLayout)), true
+	}
+
+	// 获取基础字段内容
+	content, ok := resolveField(ctx, field.name, t.converter)
+	if !ok {
+		return "", false
+	}
+
+	// 处理操作链，多值字段未被操作符合并时使用默认分隔符连接
+	result := applyOperations(steps, content)
+	result = strings.ReplaceAll(result, ExtractAllOperationGap, listSeparator)
+	if !markupFields[field.name] {
+		result = escapeText(ctx.parseMode, result)
+	}
+	return result, true
+}
+
+// withoutEscapeMarkdown 移除操作链中的 escape-md，其他解析模式会自动转义字段内容
+func withoutEscapeMarkdown(steps []operationStep) []operationStep {
+	var result []operationStep
+	for _, step := range steps {
+		if _, ok := step.op.(*EscapeMarkdownOperation); !ok {
+			result = append(result, step)
+		}
+	}
+	return result
+}
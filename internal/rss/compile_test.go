@@ -0,0 +1,70 @@
+package rss
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateCache(t *testing.T) {
+	handler := &RssHandler{}
+	text := "{title|extract:(\\d+)折|suffix:折}"
+
+	tpl := handler.templateFor("", text)
+	assert.Same(t, tpl, handler.templateFor(config.TemplateEngineBrace, text))
+	assert.NotSame(t, tpl, handler.templateFor(config.TemplateEngineGo, text))
+
+	// 正则表达式已预编译
+	steps := tpl.(*braceTemplate).nodes[0].field.steps
+	assert.IsType(t, compiledOperation(nil), steps[0].op)
+
+	handler.UpdateConfig(&config.Config{})
+	assert.NotSame(t, tpl, handler.templateFor(config.TemplateEngineBrace, text))
+}
+
+func TestTemplateConcurrentRender(t *testing.T) {
+	handler := &RssHandler{}
+	templates := []struct {
+		engine, text string
+	}{
+		{config.TemplateEngineBrace, "{title|extract:(\\d+)折} {?link}{link}{/link}"},
+		{config.TemplateEngineGo, `{{.Title | extract "(\\d+)折"}} {{field "link"}}`},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		item := &gofeed.Item{Title: "测试 8折", Link: "https://example.com/" + string(rune('a'+i))}
+		for _, tt := range templates {
+			wg.Add(1)
+			go func(engine, text string, item *gofeed.Item) {
+				defer wg.Done()
+				result := handler.renderMessage(engine, newItemContext("", nil, item), text)
+				assert.Equal(t, "8 "+item.Link, result)
+			}(tt.engine, tt.text, item)
+		}
+	}
+	wg.Wait()
+}
+
+func TestRenderDefaultTemplate(t *testing.T) {
+	handler := &RssHandler{}
+	item := &gofeed.Item{Title: "标题", Description: "<p>内容</p>", Link: "https://example.com/a"}
+
+	tests := []struct {
+		engine, parseMode string
+		expected          string
+	}{
+		{config.TemplateEngineBrace, "", "📰 *标题*\n\n内容\n\n🔗 [阅读原文](https://example.com/a)"},
+		{config.TemplateEngineGo, "", "📰 *标题*\n\n内容\n\n🔗 [阅读原文](https://example.com/a)"},
+		{config.TemplateEngineGo, config.ParseModeHTML, "📰 <b>标题</b>\n\n内容\n\n🔗 <a href=\"https://example.com/a\">阅读原文</a>"},
+		{config.TemplateEngineBrace, config.ParseModeNone, "📰 标题\n\n内容\n\n🔗 https://example.com/a"},
+	}
+	for _, tt := range tests {
+		ctx := newItemContext("", nil, item)
+		ctx.parseMode = tt.parseMode
+		assert.Equal(t, tt.expected, handler.renderMessage(tt.engine, ctx, ""), "%s %s", tt.engine, tt.parseMode)
+	}
+}
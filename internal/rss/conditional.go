@@ -0,0 +1,67 @@
+package rss
+
+//花括号语法的条件区块
+//{?field}...{/field} 字段非空时保留区块内容，{!field}...{/field} 字段为空时保留
+//条件字段支持操作链，例如 {?description|extract:价格：(\d+)元}...{/description}
+//条件中的正则包含花括号时使用带空格的语法：{ ?description|extract:(\d{4}) }
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 条件区块标签，分组 1/3 为标签类型（? ! /），分组 2/4 为字段
+var conditionTagRegex = regexp.MustCompile(`\{ ([?!/])(.*?) \}|\{([?!/])([^{}]*)\}`)
+
+// conditionTag 模板中的一个条件标签
+type conditionTag struct {
+	start, end int
+	kind       byte // ? ! /
+	field      string
+}
+
+// findConditionTags 查找模板中所有条件标签
+func findConditionTags(template string) []conditionTag {
+	var tags []conditionTag
+	for _, m := range conditionTagRegex.FindAllStringSubmatchIndex(template, -1) {
+		tag := conditionTag{start: m[0], end: m[1]}
+		if m[2] >= 0 {
+			tag.kind = template[m[2]]
+			tag.field = template[m[4]:m[5]]
+		} else {
+			tag.kind = template[m[6]]
+			tag.field = template[m[8]:m[9]]
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// conditionFieldName 条件字段的基础字段名
+func conditionFieldName(field string) string {
+	return strings.TrimSpace(strings.SplitN(field, "|", 2)[0])
+}
+
+// checkConditionTags 检查条件区块是否正确闭合，闭合标签必须与最近的未闭合区块的字段名一致
+func checkConditionTags(template string, tags []conditionTag) error {
+	var stack []conditionTag
+	for _, tag := range tags {
+		if tag.kind != '/' {
+			stack = append(stack, tag)
+			continue
+		}
+		if len(stack) == 0 {
+			return newTemplateError(template, tag.start, "unexpected closing tag {/%s}", tag.field)
+		}
+		if name := conditionFieldName(stack[len(stack)-1].field); strings.TrimSpace(tag.field) != name {
+			return newTemplateError(template, tag.start, "closing tag {/%s} does not match {?%s}", tag.field, name)
+		}
+		stack = stack[:len(stack)-1]
+	}
+
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return newTemplateError(template, top.start, "unclosed conditional block {?%s}", conditionFieldName(top.field))
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package rss
+
+import (
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessageConditionals(t *testing.T) {
+	handler := &RssHandler{}
+	item := &gofeed.Item{
+		Title:       "测试标题",
+		Description: "价格：1234元",
+		Link:        "https://example.com",
+		Authors:     []*gofeed.Person{{Name: "张三"}},
+	}
+	ctx := newItemContext("", nil, item)
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "Present field",
+			template: "{title}{?author} by {author}{/author}",
+			expected: "测试标题 by 张三",
+		},
+		{
+			name:     "Empty field",
+			template: "{title}\n{?categories}分类：{categories}\n{/categories}🔗 {link}",
+			expected: "测试标题\n🔗 https://example.com",
+		},
+		{
+			name:     "Negated block",
+			template: "{!categories}无分类{/categories}{?categories}{categories}{/categories}",
+			expected: "无分类",
+		},
+		{
+			name:     "Condition with operations",
+			template: "{?description|extract:价格：(\\d+)元}价格：{description|extract:价格：(\\d+)元}\n{/description}{?description|extract:类型：(.*)}类型：{description|extract:类型：(.*)}{/description}",
+			expected: "价格：1234",
+		},
+		{
+			name:     "Condition with braces in regex",
+			template: "{ ?description|extract:(\\d{4}) }四位数{/description}",
+			expected: "四位数",
+		},
+		{
+			name:     "Nested blocks",
+			template: "{?author}作者：{author}{?categories}（{categories}）{/categories}{?link} {link}{/link}{/author}",
+			expected: "作者：张三 https://example.com",
+		},
+		{
+			name:     "Nested inside empty block",
+			template: "A{?categories}{?author}{author}{/author}{/categories}B",
+			expected: "AB",
+		},
+		{
+			name:     "Unknown field is empty",
+			template: "{?unknown}隐藏{/unknown}显示",
+			expected: "显示",
+		},
+		{
+			name:     "Unbalanced block is kept as is",
+			template: "{?author}{author}",
+			expected: "{?author}张三",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, handler.formatMessage(ctx, tt.template))
+		})
+	}
+}
+
+func TestConditionalErrors(t *testing.T) {
+	for _, template := range []string{
+		"{?title}",
+		"{/title}",
+		"{?title}{?link}{/title}{/link}",
+	} {
+		_, err := compileBraceTemplate(template, true)
+		assert.Error(t, err, template)
+	}
+}
@@ -0,0 +1,236 @@
+package rss
+
+//摘要模式：新文章先加入持久化队列，到达推送时间后汇总为一条消息推送
+//内容超过消息长度限制时拆分为多条消息
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	// Telegram 单条消息的最大长度（UTF-16 编码单元）
+	maxMessageLength = 4096
+
+	// 摘要中每篇文章的默认模板，markdown 和 markdownv2 解析模式使用
+	defaultDigestItemTemplate   = "• [{title}]({link})"
+	defaultGoDigestItemTemplate = "• [{{.Title}}]({{.Link}})"
+	// html 解析模式的默认模板
+	defaultHTMLDigestItemTemplate   = `• <a href="{link}">{title}</a>`
+	defaultGoHTMLDigestItemTemplate = `• <a href="{{.Link}}">{{.Title}}</a>`
+	// none 解析模式的默认模板
+	defaultPlainDigestItemTemplate   = "• {title} {link}"
+	defaultGoPlainDigestItemTemplate = "• {{.Title}} {{.Link}}"
+)
+
+// messageLength 按 Telegram 的计算方式返回消息长度（UTF-16 编码单元），emoji 等字符占两个单元
+func messageLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// digestPart 拆分后的一条摘要消息
+type digestPart struct {
+	message string // 为空时表示只包含无法推送的过长文章
+	count   int    // 从队列中移除的文章数量
+}
+
+// enqueueDigest 将新文章加入各频道的摘要队列
+func (h *RssHandler) enqueueDigest(feedConfig config.FeedConfig, feed *gofeed.Feed, newItems []*gofeed.Item) {
+	info := feedInfo(feed)
+	for _, item := range newItems {
+		itemID := generateItemID(item)
+		for _, channel := range feedConfig.Channels {
+			if h.storage.IsItemSeen(feedConfig.URL, feedConfig.Name, channel, itemID) {
+				continue
+			}
+			if err := h.storage.EnqueueDigest(feedConfig.URL, channel, itemID, info, item); err != nil {
+				log.Printf("EnqueueDigest ERROR!!  channel %s: %v", channel, err)
+				continue
+			}
+			// 已持久化到队列，标记为已处理，避免重复入队
+			if err := h.storage.MarkItemSeen(feedConfig.URL, feedConfig.Name, channel, itemID); err != nil {
+				log.Printf("MarkItemSeen ERROR!!  channel %s: %v", channel, err)
+			}
+		}
+	}
+}
+
+// processDigest 到达推送时间后推送各频道的摘要
+func (h *RssHandler) processDigest(feedConfig config.FeedConfig) {
+	schedule, err := config.ParseSchedule(feedConfig.Digest.Schedule, feedConfig.Digest.Timezone)
+	if err != nil {
+		log.Printf("Invalid digest schedule for feed %s: %v", feedConfig.Name, err)
+		return
+	}
+
+	now := time.Now()
+	for _, channel := range feedConfig.Channels {
+		queued, since := h.storage.PendingDigest(feedConfig.URL, channel)
+		if since.IsZero() || now.Before(schedule.Next(since)) {
+			continue
+		}
+		// 免打扰时段推迟到时段结束后推送
+		quietMode := quietModeAt(feedConfig, channel, now)
+		if quietMode == config.QuietModeQueue {
+			continue
+		}
+
+		if len(queued) == 0 {
+			// 本周期没有新文章，直接开始新的周期
+			if err := h.storage.CompleteDigest(feedConfig.URL, channel, 0, true); err != nil {
+				log.Printf("CompleteDigest ERROR!!  channel %s: %v", channel, err)
+			}
+			continue
+		}
+
+		items := make([]*itemContext, 0, len(queued))
+		for _, q := range queued {
+			items = append(items, h.contextFor(feedConfig, q.Feed, q.Item))
+		}
+		parts := h.buildDigestMessages(feedConfig, items, now.In(schedule.Location))
+		sendOpts := channelSendOptions(feedConfig, channel)
+		if quietMode == config.QuietModeSilent {
+			sendOpts.DisableNotification = true
+		}
+
+		for i, part := range parts {
+			// 只包含过长文章的部分没有消息内容，直接移出队列
+			if part.message != "" {
+				if _, err := h.sendWithRetry(channel, part.message, sendOpts); err != nil {
+					// 未发送的文章保留在队列中，下次检查时重试
+					log.Printf("digest send Failed. feed %s channel 「%s」: %v", feedConfig.Name, channel, err)
+					break
+				}
+				log.Printf("Digest sent to channel %s: feed %s, %d items", channel, feedConfig.Name, part.count)
+				h.pause()
+			}
+			finished := i == len(parts)-1
+			if err := h.storage.CompleteDigest(feedConfig.URL, channel, part.count, finished); err != nil {
+				log.Printf("CompleteDigest ERROR!!  channel %s: %v", channel, err)
+				break
+			}
+		}
+	}
+}
+
+// buildDigestMessages 渲染摘要消息，超过长度限制时拆分
+func (h *RssHandler) buildDigestMessages(feedConfig config.FeedConfig, items []*itemContext, now time.Time) []digestPart {
+	tpl, itemTpl := digestTemplates(feedConfig)
+
+	render := func(lines []string, count int) string {
+		return strings.TrimSpace(strings.NewReplacer(
+			"{name}", escapeText(feedConfig.ParseMode, feedConfig.Name),
+			"{date}", escapeText(feedConfig.ParseMode, now.Format("2006-01-02")),
+			"{count}", strconv.Itoa(count),
+			"{items}", strings.Join(lines, "\n"),
+		).Replace(tpl))
+	}
+
+	// 每条消息中文章列表可用的长度，使用单个字符的占位内容计算，render 会去除空列表两侧的换行
+	budget := maxMessageLength - messageLength(render([]string{"-"}, len(items))) + 1
+
+	var parts []digestPart
+	var lines []string
+	size := 0
+	dropped := 0 // 无法推送的过长文章，随当前消息一起移出队列
+	flush := func() {
+		if len(lines) > 0 {
+			parts = append(parts, digestPart{message: render(lines, len(lines)), count: len(lines) + dropped})
+		} else if dropped > 0 {
+			parts = append(parts, digestPart{count: dropped})
+		}
+		lines, size, dropped = nil, 0, 0
+	}
+
+	for _, ctx := range items {
+		line, ok := h.renderDigestLine(feedConfig, ctx, itemTpl, budget)
+		if !ok {
+			log.Printf("Digest item is too long, skipped: feed %s: %s", feedConfig.Name, ctx.item.Title)
+			dropped++
+			continue
+		}
+		lineSize := messageLength(line)
+		if size > 0 && size+1+lineSize > budget {
+			flush()
+		}
+		if size > 0 {
+			size++ // 换行符
+		}
+		lines = append(lines, line)
+		size += lineSize
+	}
+	flush()
+
+	return parts
+}
+
+// digestTemplates 返回摘要模板和文章模板，没有设置时使用解析模式和模板引擎对应的默认模板
+func digestTemplates(feedConfig config.FeedConfig) (string, string) {
+	tpl := feedConfig.Digest.Template
+	if tpl == "" {
+		tpl = "📰 " + boldText(feedConfig.ParseMode, "{name}") + "（{count}）\n\n{items}"
+	}
+	itemTpl := feedConfig.Digest.ItemTemplate
+	if itemTpl != "" {
+		return tpl, itemTpl
+	}
+
+	goEngine := feedConfig.TemplateEngine == config.TemplateEngineGo
+	switch {
+	case feedConfig.ParseMode == config.ParseModeHTML && goEngine:
+		itemTpl = defaultGoHTMLDigestItemTemplate
+	case feedConfig.ParseMode == config.ParseModeHTML:
+		itemTpl = defaultHTMLDigestItemTemplate
+	case feedConfig.ParseMode == config.ParseModeNone && goEngine:
+		itemTpl = defaultGoPlainDigestItemTemplate
+	case feedConfig.ParseMode == config.ParseModeNone:
+		itemTpl = defaultPlainDigestItemTemplate
+	case goEngine:
+		itemTpl = defaultGoDigestItemTemplate
+	default:
+		itemTpl = defaultDigestItemTemplate
+	}
+	return tpl, itemTpl
+}
+
+// renderDigestLine 渲染摘要中的单篇文章，超过 budget 时缩短标题后重新渲染
+// 不直接截断渲染结果，避免截断 [标题](链接) 等格式；缩短标题后仍然过长时返回 false
+func (h *RssHandler) renderDigestLine(feedConfig config.FeedConfig, ctx *itemContext, itemTpl string, budget int) (string, bool) {
+	line := h.renderMessage(feedConfig.TemplateEngine, ctx, itemTpl)
+	if line == "" {
+		line = escapeText(ctx.parseMode, ctx.item.Title)
+	}
+	excess := messageLength(line) - budget
+	if excess <= 0 {
+		return line, true
+	}
+
+	title := []rune(ctx.item.Title)
+	keep := len(title) - excess - 1 // 1 为省略号
+	if keep <= 0 {
+		return "", false
+	}
+	item := *ctx.item
+	item.Title = string(title[:keep]) + "…"
+	shortened := *ctx
+	shortened.item = &item
+
+	line = h.renderMessage(feedConfig.TemplateEngine, &shortened, itemTpl)
+	if line == "" {
+		line = escapeText(ctx.parseMode, item.Title)
+	}
+	if messageLength(line) > budget {
+		return "", false
+	}
+	return line, true
+}
@@ -0,0 +1,122 @@
+package rss
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildDigestMessages(t *testing.T) {
+	handler := &RssHandler{}
+	now := time.Date(2024, 12, 10, 9, 0, 0, 0, time.UTC)
+
+	feedConfig := config.FeedConfig{
+		Name: "测试",
+		Digest: config.DigestConfig{
+			Template:     "{name} {date}（{count}）\n{items}",
+			ItemTemplate: "- {title}",
+		},
+	}
+
+	t.Run("Single message", func(t *testing.T) {
+		items := []*itemContext{
+			newItemContext("测试", nil, &gofeed.Item{Title: "标题1"}),
+			newItemContext("测试", nil, &gofeed.Item{Title: "标题2"}),
+		}
+		parts := handler.buildDigestMessages(feedConfig, items, now)
+		assert.Equal(t, []digestPart{{message: "测试 2024-12-10（2）\n- 标题1\n- 标题2", count: 2}}, parts)
+	})
+
+	t.Run("Split at size limit", func(t *testing.T) {
+		var items []*itemContext
+		for i := 0; i < 100; i++ {
+			item := &gofeed.Item{Title: fmt.Sprintf("%03d%s", i, strings.Repeat("长", 100))}
+			items = append(items, newItemContext("测试", nil, item))
+		}
+		parts := handler.buildDigestMessages(feedConfig, items, now)
+		assert.Greater(t, len(parts), 1)
+
+		total := 0
+		for _, part := range parts {
+			assert.LessOrEqual(t, messageLength(part.message), maxMessageLength)
+			assert.Contains(t, part.message, fmt.Sprintf("（%d）", part.count))
+			total += part.count
+		}
+		assert.Equal(t, len(items), total)
+	})
+
+	t.Run("Count emoji as two units", func(t *testing.T) {
+		var items []*itemContext
+		for i := 0; i < 100; i++ {
+			item := &gofeed.Item{Title: fmt.Sprintf("%03d%s", i, strings.Repeat("🔥", 60))}
+			items = append(items, newItemContext("测试", nil, item))
+		}
+		parts := handler.buildDigestMessages(feedConfig, items, now)
+		assert.Greater(t, len(parts), 1)
+		for _, part := range parts {
+			assert.LessOrEqual(t, messageLength(part.message), maxMessageLength)
+		}
+		assert.Equal(t, 4, messageLength("a🔥…"))
+	})
+
+	t.Run("Shorten long title", func(t *testing.T) {
+		linkConfig := feedConfig
+		linkConfig.Digest.ItemTemplate = "- [{title}]({link})"
+		items := []*itemContext{
+			newItemContext("测试", nil, &gofeed.Item{Title: strings.Repeat("长", maxMessageLength), Link: "https://example.com/a"}),
+			newItemContext("测试", nil, &gofeed.Item{Title: "短", Link: "https://example.com/b"}),
+		}
+		parts := handler.buildDigestMessages(linkConfig, items, now)
+		assert.Len(t, parts, 2)
+		for _, part := range parts {
+			assert.LessOrEqual(t, messageLength(part.message), maxMessageLength)
+			assert.Equal(t, 1, part.count)
+		}
+		// 缩短标题，保留完整的链接格式
+		assert.True(t, strings.HasSuffix(parts[0].message, "…](https://example.com/a)"))
+		assert.True(t, strings.HasSuffix(parts[1].message, "- [短](https://example.com/b)"))
+	})
+
+	t.Run("Skip item that cannot be shortened", func(t *testing.T) {
+		longLink := "https://example.com/" + strings.Repeat("a", maxMessageLength)
+		linkConfig := feedConfig
+		linkConfig.Digest.ItemTemplate = "- [{title}]({link})"
+		items := []*itemContext{
+			newItemContext("测试", nil, &gofeed.Item{Title: "标题1", Link: longLink}),
+			newItemContext("测试", nil, &gofeed.Item{Title: "标题2", Link: "https://example.com/b"}),
+			newItemContext("测试", nil, &gofeed.Item{Title: "标题3", Link: longLink}),
+		}
+		parts := handler.buildDigestMessages(linkConfig, items, now)
+		// 跳过的文章随同一条消息移出队列
+		assert.Equal(t, []digestPart{
+			{message: "测试 2024-12-10（1）\n- [标题2](https://example.com/b)", count: 3},
+		}, parts)
+
+		parts = handler.buildDigestMessages(linkConfig, items[:1], now)
+		assert.Equal(t, []digestPart{{count: 1}}, parts)
+	})
+
+	t.Run("Default templates follow parse mode", func(t *testing.T) {
+		item := &gofeed.Item{Title: "A & B.", Link: "https://example.com/a?x=1&y=2"}
+		tests := []struct {
+			parseMode string
+			want      string
+		}{
+			{"", "📰 *a.b*（1）\n\n• [A & B.](https://example.com/a?x=1&y=2)"},
+			{config.ParseModeMarkdownV2, "📰 *a\\.b*（1）\n\n• [A & B\\.](https://example\\.com/a?x\\=1&y\\=2)"},
+			{config.ParseModeHTML, "📰 <b>a.b</b>（1）\n\n• <a href=\"https://example.com/a?x=1&amp;y=2\">A &amp; B.</a>"},
+			{config.ParseModeNone, "📰 a.b（1）\n\n• A & B. https://example.com/a?x=1&y=2"},
+		}
+		for _, tt := range tests {
+			modeConfig := config.FeedConfig{Name: "a.b", ParseMode: tt.parseMode}
+			ctx := handler.contextFor(modeConfig, nil, item)
+			parts := handler.buildDigestMessages(modeConfig, []*itemContext{ctx}, now)
+			assert.Equal(t, []digestPart{{message: tt.want, count: 1}}, parts, tt.parseMode)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package rss
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/storage"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/stretchr/testify/assert"
+)
+
+// dryRunRecords 读取 dry-run 的记录并清空输出
+func dryRunRecords(t *testing.T, out *bytes.Buffer) []telegram.Record {
+	var records []telegram.Record
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		var record telegram.Record
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	out.Reset()
+	return records
+}
+
+func TestDryRun(t *testing.T) {
+	items := `<item><title>A</title><link>https://example.com/a</link><pubDate>Mon, 09 Dec 2024 08:00:00 GMT</pubDate></item>
+<item><title>B</title><link>https://example.com/b</link><pubDate>Tue, 10 Dec 2024 08:00:00 GMT</pubDate></item>`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0"><channel><title>示例</title>` + items + `</channel></rss>`))
+	}))
+	defer server.Close()
+
+	dataDir := t.TempDir()
+	store, err := storage.NewStorage(dataDir)
+	assert.NoError(t, err)
+	store.SetReadOnly(true)
+
+	var out bytes.Buffer
+	cfg := &config.Config{Feeds: []config.FeedConfig{
+		{
+			Name:      "示例",
+			URL:       server.URL,
+			FirstPush: true,
+			Channels:  []string{"@a"},
+			Template:  "{title} {link}",
+		},
+		{
+			Name:     "首次不推送",
+			URL:      server.URL + "/quiet",
+			Channels: []string{"@b"},
+			Template: "{title} {link}",
+		},
+	}}
+	handler := NewRssHandler(cfg, telegram.NewRecorder(&out), store)
+	handler.SetDryRun(true)
+
+	// 第一次检查：first_push 为 false 的 feed 只标记已有文章
+	assert.NoError(t, handler.ProcessFeeds())
+	var texts []string
+	for i, record := range dryRunRecords(t, &out) {
+		assert.Equal(t, telegram.ActionSend, record.Action)
+		assert.Equal(t, "@a", record.Channel)
+		assert.Equal(t, i+1, record.MessageID)
+		texts = append(texts, record.Text)
+	}
+	assert.ElementsMatch(t, []string{"A https://example.com/a", "B https://example.com/b"}, texts)
+
+	// 已处理的文章记录在内存中，之后的检查只处理新文章
+	assert.NoError(t, handler.ProcessFeeds())
+	assert.Empty(t, dryRunRecords(t, &out))
+
+	items += `<item><title>C</title><link>https://example.com/c</link><pubDate>Wed, 11 Dec 2024 08:00:00 GMT</pubDate></item>`
+	assert.NoError(t, handler.ProcessFeeds())
+	texts = nil
+	for _, record := range dryRunRecords(t, &out) {
+		texts = append(texts, record.Channel+" "+record.Text)
+	}
+	assert.ElementsMatch(t, []string{"@a C https://example.com/c", "@b C https://example.com/c"}, texts)
+
+	// 不写入任何状态文件
+	files, err := os.ReadDir(dataDir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+// dry-run 中仍会调用 MarkItemSeen、EnqueueDigest 和 HoldItem，由只读存储只在内存中记录，
+// 之后的正式运行推送与 dry-run 相同的文章
+func TestDryRunThenRealRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0"?>
+<rss version="2.0"><channel><title>示例</title>
+<item><title>A</title><link>https://example.com/a</link><pubDate>Mon, 09 Dec 2024 08:00:00 GMT</pubDate></item>
+<item><title>B</title><link>https://example.com/b</link><pubDate>Tue, 10 Dec 2024 08:00:00 GMT</pubDate></item>
+</channel></rss>`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Feeds: []config.FeedConfig{
+		{Name: "示例", URL: server.URL, FirstPush: true, Channels: []string{"@a"}, Template: "{title} {link}"},
+		{Name: "首次不推送", URL: server.URL + "/quiet", Channels: []string{"@b"}, Template: "{title} {link}"},
+		{
+			Name:      "摘要",
+			URL:       server.URL + "/digest",
+			FirstPush: true,
+			Channels:  []string{"@c"},
+			Template:  "{title} {link}",
+			Delivery:  config.DeliveryDigest,
+			Digest:    config.DigestConfig{Schedule: "every 24h"},
+		},
+		{
+			Name:            "免打扰",
+			URL:             server.URL + "/held",
+			FirstPush:       true,
+			Channels:        []string{"@d"},
+			Template:        "{title} {link}",
+			DeliveryOptions: config.DeliveryOptions{QuietHours: quietHours(true, config.QuietModeQueue)},
+		},
+	}}
+	dataDir := t.TempDir()
+
+	// run 执行一次检查，返回发送的消息、@c 待推送的摘要文章数和 @d 暂存的文章数
+	run := func(readOnly bool) ([]string, int, int) {
+		store, err := storage.NewStorage(dataDir)
+		assert.NoError(t, err)
+		store.SetReadOnly(readOnly)
+		var out bytes.Buffer
+		handler := NewRssHandler(cfg, telegram.NewRecorder(&out), store)
+		handler.SetDryRun(readOnly)
+		assert.NoError(t, handler.ProcessFeeds())
+
+		var texts []string
+		for _, record := range dryRunRecords(t, &out) {
+			texts = append(texts, record.Channel+" "+record.Text)
+		}
+		queued, _ := store.PendingDigest(server.URL+"/digest", "@c")
+		return texts, len(queued), len(store.HeldItems(server.URL+"/held", "@d"))
+	}
+
+	dryTexts, dryQueued, dryHeld := run(true)
+	assert.ElementsMatch(t, []string{"@a A https://example.com/a", "@a B https://example.com/b"}, dryTexts)
+	assert.Equal(t, 2, dryQueued)
+	assert.Equal(t, 2, dryHeld)
+	files, err := os.ReadDir(dataDir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	// 正式运行推送与 dry-run 相同的文章，摘要队列和暂存的文章相同
+	texts, queued, held := run(false)
+	assert.ElementsMatch(t, dryTexts, texts)
+	assert.Equal(t, dryQueued, queued)
+	assert.Equal(t, dryHeld, held)
+
+	// 正式运行的状态已保存，再次运行不重复推送
+	texts, queued, held = run(false)
+	assert.Empty(t, texts)
+	assert.Equal(t, 2, queued)
+	assert.Equal(t, 2, held)
+}
@@ -0,0 +1,330 @@
+package rss
+
+//模板字段解析
+//支持文章字段、feed 字段以及扩展字段
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"github.com/mmcdole/gofeed/rss"
+)
+
+// 多值字段（如 categories）和 extract-all 的结果在操作链结束后使用的分隔符
+// 与早期版本 prefix 操作符的连接方式一致（两个空格），保持已有模板的输出不变
+const listSeparator = "  "
+
+// 编译正则表达式，用于将图片标记转换为链接
+var imgRegex = regexp.MustCompile(`!\[(.*?)\]\((.*?)\)`)
+
+// itemContext 渲染模板时可用的数据
+type itemContext struct {
+	feedName string       // 配置中的 feed 名称
+	feed     *gofeed.Feed // 可能为 nil
+	item     *gofeed.Item
+	location *time.Location // 时间字段使用的时区，为 nil 时保持原始时区
+	tags     config.TagsConfig
+
+	parseMode string // 消息解析模式，决定字段内容的转换和转义
+}
+
+func newItemContext(feedName string, feed *gofeed.Feed, item *gofeed.Item) *itemContext {
+	return &itemContext{feedName: feedName, feed: feed, item: item}
+}
+
+// feedInfo 复制 feed 的元数据（不含文章列表），用于持久化队列
+func feedInfo(feed *gofeed.Feed) *gofeed.Feed {
+	if feed == nil {
+		return nil
+	}
+	info := *feed
+	info.Items = nil
+	return &info
+}
+
+// resolveField 获取字段内容，不支持的字段返回 false
+// 多值字段使用 ExtractAllOperationGap 连接，可配合 prefix 等操作符使用
+// description/content 按解析模式转换格式，其他字段为原始文本，由调用方在操作链之后转义
+func resolveField(ctx *itemContext, name string, converter *md.Converter) (string, bool) {
+	item := ctx.item
+
+	switch {
+	case strings.HasPrefix(name, "ext:"):
+		return extensionField(item.Extensions, strings.TrimPrefix(name, "ext:")), true
+	case strings.HasPrefix(name, "itunes:"):
+		return itunesField(item.ITunesExt, strings.TrimPrefix(name, "itunes:")), true
+	}
+
+	switch name {
+	case "title":
+		return item.Title, true
+	case "description":
+		return convertHTML(ctx.parseMode, item.Description, converter), true
+	case "content":
+		return convertHTML(ctx.parseMode, item.Content, converter), true
+	case "link":
+		return item.Link, true
+	case "pubDate", "updated":
+		if t, _ := resolveTimeField(ctx, name); t != nil {
+			return t.Format(defaultTimeLayout), true
+		}
+		return "", true
+	case "guid":
+		return item.GUID, true
+	case "author":
+		if names := personNames(item.Authors, item.Author); len(names) > 0 {
+			return names[0], true
+		}
+		return "", true
+	case "authors":
+		return strings.Join(personNames(item.Authors, item.Author), ExtractAllOperationGap), true
+	case "categories":
+		return strings.Join(item.Categories, ExtractAllOperationGap), true
+	case "tags":
+		return strings.Join(itemTags(item, ctx.tags), ExtractAllOperationGap), true
+	case "enclosure":
+		if len(item.Enclosures) > 0 {
+			return item.Enclosures[0].URL, true
+		}
+		return "", true
+	case "enclosures":
+		var urls []string
+		for _, enclosure := range item.Enclosures {
+			urls = append(urls, enclosure.URL)
+		}
+		return strings.Join(urls, ExtractAllOperationGap), true
+	case "image":
+		return itemImage(item), true
+	case "comments":
+		return item.Custom["comments"], true
+	case "feedName":
+		return ctx.feedName, true
+	case "feedTitle":
+		if ctx.feed != nil {
+			return ctx.feed.Title, true
+		}
+		return "", true
+	case "feedLink":
+		if ctx.feed != nil {
+			return ctx.feed.Link, true
+		}
+		return "", true
+	case "feedDescription":
+		if ctx.feed != nil {
+			return ctx.feed.Description, true
+		}
+		return "", true
+	case "feedImage":
+		if ctx.feed != nil && ctx.feed.Image != nil {
+			return ctx.feed.Image.URL, true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// resolveTimeField 获取时间字段，已转换到上下文的时区；不是时间字段时返回 false
+func resolveTimeField(ctx *itemContext, name string) (*time.Time, bool) {
+	var t *time.Time
+	switch name {
+	case "pubDate":
+		t = ctx.item.PublishedParsed
+	case "updated":
+		t = ctx.item.UpdatedParsed
+	default:
+		return nil, false
+	}
+	return ctx.inLocation(t), true
+}
+
+// inLocation 将时间转换到上下文的时区
+func (ctx *itemContext) inLocation(t *time.Time) *time.Time {
+	if t == nil || ctx.location == nil {
+		return t
+	}
+	local := t.In(ctx.location)
+	return &local
+}
+
+// 将 HTML 转换为 Markdown，并将图片标记转换为链接
+func htmlToMarkdown(html string, converter *md.Converter) string {
+	if html == "" {
+		return ""
+	}
+	mdContent, err := converter.ConvertString(html)
+	if err != nil {
+		log.Printf("Error converting HTML to Markdown: %v", err)
+		return html
+	}
+	return imgRegex.ReplaceAllString(mdContent, "[Media]($2)")
+}
+
+// 获取作者名称列表，兼容旧的 Author 字段
+func personNames(authors []*gofeed.Person, author *gofeed.Person) []string {
+	if len(authors) == 0 && author != nil {
+		authors = []*gofeed.Person{author}
+	}
+	var names []string
+	for _, person := range authors {
+		if person == nil {
+			continue
+		}
+		name := person.Name
+		if name == "" {
+			name = person.Email
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// itemTags 由分类和关键词映射生成话题标签，去重并限制数量
+func itemTags(item *gofeed.Item, tagsConfig config.TagsConfig) []string {
+	candidates := append([]string{}, item.Categories...)
+
+	// 关键词按字典序匹配，保证输出稳定
+	keywords := make([]string, 0, len(tagsConfig.Keywords))
+	for keyword := range tagsConfig.Keywords {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	text := strings.ToLower(item.Title + "\n" + strings.Join(item.Categories, "\n"))
+	for _, keyword := range keywords {
+		if strings.Contains(text, strings.ToLower(keyword)) {
+			candidates = append(candidates, tagsConfig.Keywords[keyword])
+		}
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		tag := toHashtag(candidate)
+		// Telegram 话题标签不区分大小写
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+		if tagsConfig.Limit > 0 && len(tags) >= tagsConfig.Limit {
+			break
+		}
+	}
+	return tags
+}
+
+// 获取文章图片：优先使用 image 字段，其次是图片类型的附件
+func itemImage(item *gofeed.Item) string {
+	if item.Image != nil && item.Image.URL != "" {
+		return item.Image.URL
+	}
+	for _, enclosure := range item.Enclosures {
+		if strings.HasPrefix(enclosure.Type, "image/") {
+			return enclosure.URL
+		}
+	}
+	return ""
+}
+
+// extensionField 获取扩展字段
+// 格式：<prefix>:<name>[/<child>...][@<attr>]，例如 media:thumbnail@url、media:group/content@url
+func extensionField(extensions ext.Extensions, path string) string {
+	path, attr, _ := strings.Cut(path, "@")
+	prefix, name, found := strings.Cut(path, ":")
+	if !found || extensions == nil {
+		return ""
+	}
+
+	names := strings.Split(name, "/")
+	values := extensions[prefix][names[0]]
+	for _, child := range names[1:] {
+		if len(values) == 0 {
+			return ""
+		}
+		values = values[0].Children[child]
+	}
+	if len(values) == 0 {
+		return ""
+	}
+
+	if attr != "" {
+		return values[0].Attrs[attr]
+	}
+	return values[0].Value
+}
+
+// itunesField 获取 iTunes 扩展字段
+func itunesField(itunes *ext.ITunesItemExtension, name string) string {
+	if itunes == nil {
+		return ""
+	}
+	switch name {
+	case "author":
+		return itunes.Author
+	case "duration":
+		return itunes.Duration
+	case "explicit":
+		return itunes.Explicit
+	case "keywords":
+		return itunes.Keywords
+	case "subtitle":
+		return itunes.Subtitle
+	case "summary":
+		return itunes.Summary
+	case "image":
+		return itunes.Image
+	case "episode":
+		return itunes.Episode
+	case "season":
+		return itunes.Season
+	case "episodeType":
+		return itunes.EpisodeType
+	}
+	return ""
+}
+
+// newParser 创建 feed 解析器
+func newParser() *gofeed.Parser {
+	parser := gofeed.NewParser()
+	parser.RSSTranslator = &rssTranslator{}
+	// 多个 feed 并发抓取，预先设置 Client，避免 gofeed 在首次请求时并发创建
+	parser.Client = &http.Client{}
+	return parser
+}
+
+// rssTranslator 在默认转换的基础上保留 RSS 的 comments 字段
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	rssFeed, ok := feed.(*rss.Feed)
+	if !ok || len(rssFeed.Items) != len(result.Items) {
+		return result, nil
+	}
+	for i, rssItem := range rssFeed.Items {
+		if rssItem.Comments == "" {
+			continue
+		}
+		if result.Items[i].Custom == nil {
+			result.Items[i].Custom = make(map[string]string)
+		}
+		result.Items[i].Custom["comments"] = rssItem.Comments
+	}
+	return result, nil
+}
@@ -0,0 +1,117 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessageItemFields(t *testing.T) {
+	handler := &RssHandler{}
+	updated := time.Date(2024, 12, 10, 8, 30, 0, 0, time.UTC)
+
+	feed := &gofeed.Feed{
+		Title: "示例站点",
+		Link:  "https://example.com",
+		Image: &gofeed.Image{URL: "https://example.com/logo.png"},
+	}
+	item := &gofeed.Item{
+		Title:         "测试标题",
+		GUID:          "guid-1",
+		UpdatedParsed: &updated,
+		Authors:       []*gofeed.Person{{Name: "张三"}, {Name: "李四"}},
+		Categories:    []string{"科技", "数码"},
+		Enclosures: []*gofeed.Enclosure{
+			{URL: "https://example.com/a.mp3", Type: "audio/mpeg"},
+			{URL: "https://example.com/b.jpg", Type: "image/jpeg"},
+		},
+		Custom:    map[string]string{"comments": "https://example.com/comments"},
+		ITunesExt: &ext.ITunesItemExtension{Duration: "01:02:03"},
+		Extensions: ext.Extensions{
+			"media": {
+				"thumbnail": {{Name: "thumbnail", Attrs: map[string]string{"url": "https://example.com/t.jpg"}}},
+				"group": {{Name: "group", Children: map[string][]ext.Extension{
+					"title": {{Name: "title", Value: "媒体标题"}},
+				}}},
+			},
+		},
+	}
+	ctx := newItemContext("示例", feed, item)
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"{author}", "张三"},
+		{"{authors}", "张三  李四"},
+		{"{categories}", "科技  数码"},
+		{"{categories|prefix:#}", "#科技  #数码"},
+		{"{updated}", "2024-12-10 08:30:00"},
+		{"{guid}", "guid-1"},
+		{"{enclosure}", "https://example.com/a.mp3"},
+		{"{image}", "https://example.com/b.jpg"},
+		{"{comments}", "https://example.com/comments"},
+		{"{feedName} {feedTitle} {feedLink} {feedImage}", "示例 示例站点 https://example.com https://example.com/logo.png"},
+		{"{ext:media:thumbnail@url}", "https://example.com/t.jpg"},
+		{"{ext:media:group/title}", "媒体标题"},
+		{"{ext:media:missing@url|default:无}", "无"},
+		{"{itunes:duration}", "01:02:03"},
+		{"{unknown}", "{unknown}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			assert.Equal(t, tt.expected, handler.formatMessage(ctx, tt.template))
+		})
+	}
+}
+
+func TestFormatMessageTags(t *testing.T) {
+	handler := &RssHandler{}
+	item := &gofeed.Item{
+		Title:      "Apple 发布 iPhone 16",
+		Categories: []string{"数码 产品", "Apple", "apple", "2024"},
+	}
+
+	tests := []struct {
+		name     string
+		tags     config.TagsConfig
+		template string
+		expected string
+	}{
+		{"Categories", config.TagsConfig{}, "{tags}", "#数码产品  #Apple  #_2024"},
+		{"Join", config.TagsConfig{}, "{tags|join: }", "#数码产品 #Apple #_2024"},
+		{
+			name:     "Keyword mapping",
+			tags:     config.TagsConfig{Keywords: map[string]string{"iphone": "手机", "安卓": "Android"}},
+			template: "{tags|join: }",
+			expected: "#数码产品 #Apple #_2024 #手机",
+		},
+		{"Limit", config.TagsConfig{Limit: 2}, "{tags|join: }", "#数码产品 #Apple"},
+		{"Prefix", config.TagsConfig{Limit: 1}, "{tags|prefix:🏷 }", "🏷 #数码产品"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newItemContext("", nil, item)
+			ctx.tags = tt.tags
+			assert.Equal(t, tt.expected, handler.formatMessage(ctx, tt.template))
+		})
+	}
+}
+
+func TestRssTranslatorComments(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>t</title>
+<item><title>a</title><link>https://example.com/a</link><comments>https://example.com/a#comments</comments></item>
+</channel></rss>`
+
+	feed, err := newParser().Parse(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/a#comments", feed.Items[0].Custom["comments"])
+}
@@ -0,0 +1,271 @@
+package rss
+
+//Go text/template 模板引擎
+//模板数据为结构化的文章和 feed 信息，模板操作符注册为模板函数
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/mmcdole/gofeed"
+)
+
+// templateData Go 模板引擎使用的数据
+// 文本字段已按解析模式转义，Description 和 Content 已转换为解析模式对应的格式
+type templateData struct {
+	Title       string
+	Description string
+	Content     string
+	Link        string
+	GUID        string
+	Author      string
+	Authors     []string
+	Categories  []string
+	Tags        []string // 话题标签，与 {tags} 相同
+	PubDate     string   // 与 {pubDate} 格式相同
+	UpdatedDate string   // 与 {updated} 格式相同
+	Published   *time.Time
+	Updated     *time.Time
+	Image       string
+	Comments    string
+	Enclosures  []*gofeed.Enclosure
+
+	Feed feedData
+	Item *gofeed.Item // 原始数据，不转义
+}
+
+// feedData 模板中 .Feed 的数据
+type feedData struct {
+	Name        string // 配置中的 feed 名称
+	Title       string
+	Link        string
+	Description string
+	Image       string
+}
+
+// newTemplateData 从渲染上下文生成模板数据
+func newTemplateData(ctx *itemContext, converter *md.Converter) *templateData {
+	field := fieldFunc(ctx, converter)
+	escape := func(values []string) []string {
+		if legacyMarkdown(ctx.parseMode) {
+			return values
+		}
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = escapeText(ctx.parseMode, value)
+		}
+		return escaped
+	}
+
+	item := ctx.item
+	return &templateData{
+		Title:       field("title"),
+		Description: field("description"),
+		Content:     field("content"),
+		Link:        field("link"),
+		GUID:        field("guid"),
+		Author:      field("author"),
+		Authors:     escape(personNames(item.Authors, item.Author)),
+		Categories:  escape(item.Categories),
+		Tags:        escape(itemTags(item, ctx.tags)),
+		PubDate:     field("pubDate"),
+		UpdatedDate: field("updated"),
+		Published:   ctx.inLocation(item.PublishedParsed),
+		Updated:     ctx.inLocation(item.UpdatedParsed),
+		Image:       field("image"),
+		Comments:    field("comments"),
+		Enclosures:  item.Enclosures,
+		Feed: feedData{
+			Name:        field("feedName"),
+			Title:       field("feedTitle"),
+			Link:        field("feedLink"),
+			Description: field("feedDescription"),
+			Image:       field("feedImage"),
+		},
+		Item: item,
+	}
+}
+
+// templateFuncs 生成模板函数
+// 模板操作符以驼峰命名注册（extract-all -> extractAll），参数在前，管道值在最后：
+// {{.Title | extract "(\\d+)折"}}、{{.Title | replace "a" "b"}}
+// 另提供 field 函数获取任意花括号语法支持的字段：{{field "ext:media:thumbnail@url"}}，渲染时绑定到文章
+// 时间值的操作结果按解析模式转义；markdown 以外的解析模式中 escapeMd 不做处理，字段已自动转义
+func templateFuncs(parseMode string) template.FuncMap {
+	processor := NewTemplateProcessor()
+	funcs := template.FuncMap{
+		"field": func(name string) string { return "" },
+	}
+	for name, op := range processor.registry.operations {
+		funcs[funcName(name)] = operationFunc(op, parseMode)
+	}
+	if !legacyMarkdown(parseMode) {
+		funcs[funcName("escape-md")] = func(args ...interface{}) string {
+			if len(args) == 0 {
+				return ""
+			}
+			return fmt.Sprint(args[len(args)-1])
+		}
+	}
+	return funcs
+}
+
+// fieldFunc 生成绑定到文章的 field 模板函数，字段内容按解析模式转义
+func fieldFunc(ctx *itemContext, converter *md.Converter) func(name string) string {
+	return func(name string) string {
+		value, _ := resolveField(ctx, name, converter)
+		value = strings.ReplaceAll(value, ExtractAllOperationGap, listSeparator)
+		if !markupFields[name] {
+			value = escapeText(ctx.parseMode, value)
+		}
+		return value
+	}
+}
+
+// 将操作符包装为模板函数，最后一个参数为管道值，其余参数以 : 连接作为操作参数
+// 管道值为时间（如 .Published）时，时间操作直接处理时间值，结果按解析模式转义
+// 可预编译的操作按参数缓存编译结果
+func operationFunc(op Operation, parseMode string) func(args ...interface{}) string {
+	var mu sync.Mutex
+	compiled := make(map[string]Operation)
+	resolve := func(params string) Operation {
+		c, ok := op.(CompilableOperation)
+		if !ok {
+			return op
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if cached, ok := compiled[params]; ok {
+			return cached
+		}
+		result, err := c.Compile(params)
+		if err != nil {
+			result = op // 参数无效时由原操作记录日志并返回原内容
+		}
+		compiled[params] = result
+		return result
+	}
+
+	return func(args ...interface{}) string {
+		if len(args) == 0 {
+			return ""
+		}
+		params := make([]string, 0, len(args)-1)
+		for _, arg := range args[:len(args)-1] {
+			params = append(params, fmt.Sprint(arg))
+		}
+		joined := strings.Join(params, ":")
+		op := resolve(joined)
+
+		switch value := args[len(args)-1].(type) {
+		case *time.Time:
+			if value == nil {
+				return op.Process("", joined)
+			}
+			return escapeText(parseMode, processTimeValue(op, *value, joined))
+		case time.Time:
+			return escapeText(parseMode, processTimeValue(op, value, joined))
+		default:
+			return op.Process(fmt.Sprint(value), joined)
+		}
+	}
+}
+
+// 时间值传给操作，不支持时间值的操作使用默认格式的文本
+func processTimeValue(op Operation, t time.Time, params string) string {
+	timeOp, ok := op.(TimeOperation)
+	if !ok {
+		return op.Process(t.Format(defaultTimeLayout), params)
+	}
+	value, result := timeOp.ProcessTime(t, params)
+	if value != nil {
+		return value.Format(defaultTimeLayout)
+	}
+	return result
+}
+
+// 操作符名称转换为模板函数名称，例如 extract-all -> extractAll
+func funcName(name string) string {
+	parts := strings.Split(name, "-")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] == "" {
+			continue
+		}
+		runes := []rune(parts[i])
+		runes[0] = unicode.ToUpper(runes[0])
+		parts[i] = string(runes)
+	}
+	return strings.Join(parts, "")
+}
+
+// goTemplate 预编译的 Go 模板
+type goTemplate struct {
+	tpl       *template.Template
+	converter *md.Converter
+
+	funcsMu sync.Mutex
+	funcs   map[string]template.FuncMap // 各解析模式的模板函数
+}
+
+// compileGoTemplate 解析 Go 模板
+func compileGoTemplate(text string) (*goTemplate, error) {
+	tpl, err := template.New("message").Funcs(templateFuncs("")).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &goTemplate{tpl: tpl, converter: newMarkdownConverter()}, nil
+}
+
+// render 使用 Go 模板引擎格式化消息
+func (t *goTemplate) render(ctx *itemContext) string {
+	// 在模板副本上绑定 field 函数，预编译的模板可并发使用
+	tpl, err := t.tpl.Clone()
+	if err != nil {
+		log.Printf("Error cloning template: %v", err)
+		return ""
+	}
+	if !legacyMarkdown(ctx.parseMode) {
+		tpl.Funcs(t.funcsFor(ctx.parseMode))
+	}
+	tpl.Funcs(template.FuncMap{"field": fieldFunc(ctx, t.converter)})
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, newTemplateData(ctx, t.converter)); err != nil {
+		log.Printf("Error executing template: %v", err)
+		return ""
+	}
+
+	message := strings.ReplaceAll(buf.String(), ExtractAllOperationGap, listSeparator)
+	return cleanMessage(message)
+}
+
+// funcsFor 获取解析模式对应的模板函数
+func (t *goTemplate) funcsFor(parseMode string) template.FuncMap {
+	t.funcsMu.Lock()
+	defer t.funcsMu.Unlock()
+	funcs, ok := t.funcs[parseMode]
+	if !ok {
+		funcs = templateFuncs(parseMode)
+		if t.funcs == nil {
+			t.funcs = make(map[string]template.FuncMap)
+		}
+		t.funcs[parseMode] = funcs
+	}
+	return funcs
+}
+
+// renderMessage 根据模板引擎格式化消息，模板为空时使用模板引擎和解析模式对应的默认模板
+func (h *RssHandler) renderMessage(engine string, ctx *itemContext, text string) string {
+	if text == "" {
+		text = config.DefaultTemplateFor(engine, ctx.parseMode)
+	}
+	return h.templateFor(engine, text).render(ctx)
+}
@@ -0,0 +1,63 @@
+package rss
+
+import (
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteGoTemplate(t *testing.T) {
+	handler := &RssHandler{}
+
+	item := &gofeed.Item{
+		Title:       "测试标题 8折",
+		Description: `<p>价格：1234元</p>`,
+		Link:        "https://example.com",
+		Categories:  []string{"科技", "数码"},
+		Extensions: ext.Extensions{
+			"media": {"thumbnail": []ext.Extension{{Name: "thumbnail", Attrs: map[string]string{"url": "https://example.com/t.jpg"}}}},
+		},
+	}
+	ctx := newItemContext("示例", &gofeed.Feed{Title: "示例站点"}, item)
+
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "Fields",
+			template: "📰 *{{.Title}}*\n{{.Feed.Name}} / {{.Feed.Title}}\n🔗 {{.Link}}",
+			expected: "📰 *测试标题 8折*\n示例 / 示例站点\n🔗 https://example.com",
+		},
+		{
+			name:     "Conditional and range",
+			template: "{{if .Categories}}分类：{{range $i, $c := .Categories}}{{if $i}} {{end}}#{{$c}}{{end}}{{end}}{{if .Author}}\n作者：{{.Author}}{{end}}",
+			expected: "分类：#科技 #数码",
+		},
+		{
+			name:     "Operators as functions",
+			template: `{{.Description | extract "价格：(\\d+)元" | replace "\\d{4}" "****"}} {{.Title | extractAll "(\\d+折)" | prefix "#"}}`,
+			expected: "**** #8折",
+		},
+		{
+			name:     "Field function",
+			template: `{{field "ext:media:thumbnail@url"}}`,
+			expected: "https://example.com/t.jpg",
+		},
+		{
+			name:     "Invalid template",
+			template: "{{.Title",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, handler.renderMessage(config.TemplateEngineGo, ctx, tt.template))
+		})
+	}
+}
@@ -5,16 +5,16 @@ import (
 	"fmt"
 	"log"
 	"math/rand"
-	"regexp"
+	"net/url"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"os"
-
 	"github.com/Hootrix/rss2telegram/internal/config"
 	"github.com/Hootrix/rss2telegram/internal/storage"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
 	md "github.com/JohannesKaufmann/html-to-markdown"
 	"github.com/mmcdole/gofeed"
 )
@@ -25,25 +25,48 @@ type RssHandler struct {
 	config  *config.Config
 	bot     TelegramBot
 	storage *storage.Storage
+
+	// dry-run 模式：不等待发送间隔
+	dryRun bool
+
+	// 预编译的模板，配置更新时清空
+	templateMu sync.Mutex
+	templates  map[templateKey]messageTemplate
 }
 
 type TelegramBot interface {
-	Send(channel string, message string) error
+	Send(channel string, message string, opts *telegram.SendOptions) (int, error)
+	Edit(channel string, messageID int, message string, opts *telegram.SendOptions) error
+	Reply(channel string, messageID int, message string, opts *telegram.SendOptions) (int, error)
+	Delete(channel string, messageID int) error
 }
 
 func NewRssHandler(cfg *config.Config, bot TelegramBot, store *storage.Storage) *RssHandler {
 	return &RssHandler{
-		parser:  gofeed.NewParser(),
+		parser:  newParser(),
 		config:  cfg,
 		bot:     bot,
 		storage: store,
 	}
 }
 
+// SetDryRun 设置 dry-run 模式，通常与不调用 Telegram API 的 TelegramBot 实现（如 telegram.Recorder）
+// 和只读模式的 storage.Storage 配合使用。dry-run 中仍按正常流程调用 MarkItemSeen、EnqueueDigest、HoldItem 等方法，
+// 由只读存储只在内存中记录，同一进程中之后的检查不重复处理，也不影响之后的正式运行
+func (h *RssHandler) SetDryRun(dryRun bool) {
+	h.dryRun = dryRun
+}
+
 func (h *RssHandler) UpdateConfig(cfg *config.Config) {
 	h.Lock()
 	defer h.Unlock()
 	h.config = cfg
+
+	h.templateMu.Lock()
+	h.templates = nil
+	h.templateMu.Unlock()
+
+	h.dropRemovedHeld(cfg)
 	log.Printf("RSS处理器配置已更新")
 }
 
@@ -73,6 +96,14 @@ func (h *RssHandler) ProcessFeeds() error {
 				log.Printf("Error processing feed %s: %v", feed.Name, err)
 				errChan <- fmt.Errorf("feed %s: %w", feed.Name, err)
 			}
+
+			// 以下定时任务与本次抓取是否成功无关
+			// 推送免打扰时段结束后暂存的文章
+			h.releaseHeld(feed)
+			// 摘要模式下检查是否到达推送时间
+			if feed.Delivery == config.DeliveryDigest {
+				h.processDigest(feed)
+			}
 		}(feed)
 	}
 
@@ -94,6 +125,31 @@ func (h *RssHandler) ProcessFeeds() error {
 	return nil
 }
 
+// contextFor 创建文章的模板渲染上下文，时间字段使用 feed 或全局配置的时区
+func (h *RssHandler) contextFor(feedConfig config.FeedConfig, feed *gofeed.Feed, item *gofeed.Item) *itemContext {
+	ctx := newItemContext(feedConfig.Name, feed, item)
+	ctx.tags = feedConfig.Tags
+	ctx.parseMode = feedConfig.ParseMode
+
+	timezone := feedConfig.Timezone
+	if timezone == "" {
+		h.RLock()
+		if h.config != nil {
+			timezone = h.config.Timezone
+		}
+		h.RUnlock()
+	}
+	if timezone != "" {
+		loc, err := config.LoadLocation(timezone)
+		if err != nil {
+			log.Printf("Invalid timezone for feed %s: %v", feedConfig.Name, err)
+		} else {
+			ctx.location = loc
+		}
+	}
+	return ctx
+}
+
 // 生成项目的唯一标识
 func generateItemID(item *gofeed.Item) string {
 	// 优先使用 GUID
@@ -115,6 +171,12 @@ func generateItemID(item *gofeed.Item) string {
 	return fmt.Sprintf("content:%x", sha256.Sum256([]byte(item.Content)))
 }
 
+// 生成文章内容的哈希，用于检测已推送文章的更新
+func contentHash(item *gofeed.Item) string {
+	data := strings.Join([]string{item.Title, item.Description, item.Content, item.Link}, "\n")
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
 func (h *RssHandler) processFeed(feedConfig config.FeedConfig) error {
 	log.Printf("Processing feed: %s (%s)", feedConfig.Name, feedConfig.URL)
 
@@ -134,9 +196,8 @@ func (h *RssHandler) processFeed(feedConfig config.FeedConfig) error {
 
 	isFirstRun := true // 用于判断是否是第一次运行
 	for _, channel := range feedConfig.Channels {
-		// 检查 bloom 文件是否存在来判断是否是第一次运行
-		bloomPath := h.storage.GetBloomFilePath(feedConfig.URL, channel)
-		if _, err := os.Stat(bloomPath); err == nil {
+		// 检查是否已有推送状态（bloom 文件或只读模式下内存中的状态）来判断是否是第一次运行
+		if h.storage.HasChannelState(feedConfig.URL, channel) {
 			isFirstRun = false
 			break
 		}
@@ -222,12 +283,37 @@ func (h *RssHandler) processFeed(feedConfig config.FeedConfig) error {
 	}
 
 	// 处理新项目（推送文章）
+	failed := 0
+	if feedConfig.Delivery == config.DeliveryDigest {
+		// 摘要模式下加入摘要队列，由 processDigest 定时推送
+		h.enqueueDigest(feedConfig, feed, newItems)
+	} else {
+		failed = h.deliverItems(feedConfig, feed, newItems)
+	}
+
+	// 处理已推送文章的内容更新
+	h.processUpdates(feedConfig, feed)
+	// 处理已从feed中移除的文章
+	h.processRemoved(feedConfig, feed.Items)
+
+	log.Printf("processFeed finish. name:%s, processed %d new items", feedConfig.Name, len(newItems))
+	if failed > 0 {
+		// 未发送成功的文章未标记为已处理，下次检查时重试
+		return fmt.Errorf("failed to send %d messages", failed)
+	}
+	return nil
+}
+
+// deliverItems 逐篇推送新文章到所有频道，返回发送失败的消息数量
+func (h *RssHandler) deliverItems(feedConfig config.FeedConfig, feed *gofeed.Feed, newItems []*gofeed.Item) int {
 	// 使用信号量控制并发数
 	sem := make(chan struct{}, 1) // 单个feed下处理channel 最大并发数为1
 	var wg sync.WaitGroup
+	var failed int32
 
 	for _, item := range newItems {
 		itemID := generateItemID(item)
+		ctx := h.contextFor(feedConfig, feed, item)
 
 		// 并发处理每个channel
 		for _, channel := range feedConfig.Channels {
@@ -237,12 +323,23 @@ func (h *RssHandler) processFeed(feedConfig config.FeedConfig) error {
 				continue
 			}
 
+			// 免打扰时段暂存文章，时段结束后由 releaseHeld 推送
+			quietMode := quietModeAt(feedConfig, channel, time.Now())
+			if quietMode == config.QuietModeQueue {
+				h.holdItem(feedConfig, channel, itemID, feed, item)
+				continue
+			}
+
 			// 格式化消息
-			message := h.formatMessage(item, feedConfig.Template)
+			message := h.renderMessage(feedConfig.TemplateEngine, ctx, feedConfig.Template)
 			if message == "" {
 				log.Printf("formatMessage Empty Result, skip. RSS item title: %s", item.Title)
 				continue
 			}
+			sendOpts := h.buildSendOptions(ctx, feedConfig, channel)
+			if quietMode == config.QuietModeSilent {
+				sendOpts.DisableNotification = true
+			}
 
 			wg.Add(1)
 			go func(channel string, item *gofeed.Item) {
@@ -250,136 +347,145 @@ func (h *RssHandler) processFeed(feedConfig config.FeedConfig) error {
 				sem <- struct{}{}        // 获取信号量
 				defer func() { <-sem }() // 释放信号量
 
-				// 多次重试发送消息（包含第一次请求）
-				maxRetries := 3
-				var sendSuccess bool
-				var lastError error
-				for i := 0; i < maxRetries; i++ {
-					if err := h.bot.Send(channel, message); err != nil {
-						lastError = err
-						if i == maxRetries-1 {
-							log.Printf("Failed to send message to channel %s after %d retries: %v", channel, maxRetries, err)
-							break
-						}
-						log.Printf("Error sending message to channel %s (retry %d/%d): %v", channel, i+1, maxRetries, err)
-						h.ExponentialBackoffWithJitter(i)
-						continue
-					}
-					log.Printf("Successfully sent message to channel %s: %s", channel, item.Title)
-					sendSuccess = true
-					break // 发送成功，退出重试循环
-				}
-
-				// 只有在发送成功后才标记为已处理
-				if sendSuccess {
-					if err := h.storage.MarkItemSeen(feedConfig.URL, feedConfig.Name, channel, itemID); err != nil {
-						log.Printf("msg send success. MarkItemSeen ERROR!!  channel %s: %v", channel, err)
-					}
-					time.Sleep(time.Second) // 发送间隔 1 秒
-				} else if lastError != nil {
-					// 如果发送失败且有错误，记录到日志
-					log.Printf("msg send Failed. item '%s' for channel 「%s」: %v", item.Title, channel, lastError)
+				if !h.deliverItem(feedConfig, channel, itemID, item, message, sendOpts) {
+					atomic.AddInt32(&failed, 1)
 				}
 			}(channel, item)
 		}
 	}
 
 	wg.Wait() // 等待所有 goroutine 完成
-
-	log.Printf("processFeed finish. name:%s, processed %d new items", feedConfig.Name, len(newItems))
-	return nil
+	return int(failed)
 }
 
-// 指数退避+随机抖动
-func (h *RssHandler) ExponentialBackoffWithJitter(attempt int) {
-	base := time.Second
-	maxJitter := 500 * time.Millisecond                    // 最大抖动 500毫秒
-	delay := base * time.Duration(1<<attempt)              // 指数退避。1<<attempt表示attemp的2次幂
-	jitter := time.Duration(rand.Int63n(int64(maxJitter))) // 随机抖动
-	time.Sleep(delay + jitter)
+// deliverItem 推送单篇文章到频道，成功后标记为已处理并记录消息
+func (h *RssHandler) deliverItem(feedConfig config.FeedConfig, channel, itemID string, item *gofeed.Item, message string, sendOpts *telegram.SendOptions) bool {
+	messageID, err := h.sendWithRetry(channel, message, sendOpts)
+	if err != nil {
+		// 如果发送失败，记录到日志
+		log.Printf("msg send Failed. item '%s' for channel 「%s」: %v", item.Title, channel, err)
+		return false
+	}
+	log.Printf("Successfully sent message to channel %s: %s", channel, item.Title)
+
+	// 只有在发送成功后才标记为已处理
+	if err := h.storage.MarkItemSeen(feedConfig.URL, feedConfig.Name, channel, itemID); err != nil {
+		log.Printf("msg send success. MarkItemSeen ERROR!!  channel %s: %v", channel, err)
+	}
+	record := storage.MessageRecord{
+		MessageID: messageID,
+		Hash:      contentHash(item),
+		Text:      message,
+		SentAt:    time.Now(),
+		Buttons:   recordButtons(sendOpts),
+	}
+	if err := h.storage.SaveMessage(feedConfig.URL, channel, itemID, record); err != nil {
+		log.Printf("msg send success. SaveMessage ERROR!!  channel %s: %v", channel, err)
+	}
+	h.pause()
+	return true
 }
 
-// 格式化消息
-func (h *RssHandler) formatMessage(item *gofeed.Item, template string) string {
-	if template == "" {
-		template = "{title}\n\n{link}" // 默认模板
+// pause 发送间隔 1 秒，避免触发 Telegram 限制；dry-run 模式下不等待
+func (h *RssHandler) pause() {
+	if !h.dryRun {
+		time.Sleep(time.Second)
 	}
+}
 
-	processor := NewTemplateProcessor()
-	converter := md.NewConverter("", true, &md.Options{
-		EscapeMode: "disabled", // 禁用转义  包括针对|的转义
-	})
+// sendWithRetry 多次重试发送消息（包含第一次请求），返回消息 ID
+func (h *RssHandler) sendWithRetry(channel string, message string, sendOpts *telegram.SendOptions) (int, error) {
+	maxRetries := 3
+	var lastError error
+	for i := 0; i < maxRetries; i++ {
+		messageID, err := h.bot.Send(channel, message, sendOpts)
+		if err == nil {
+			return messageID, nil // 发送成功，退出重试循环
+		}
+		lastError = err
+		if i == maxRetries-1 {
+			log.Printf("Failed to send message to channel %s after %d retries: %v", channel, maxRetries, err)
+			break
+		}
+		log.Printf("Error sending message to channel %s (retry %d/%d): %v", channel, i+1, maxRetries, err)
+		h.ExponentialBackoffWithJitter(i)
+	}
+	return 0, lastError
+}
 
-	// 编译正则表达式，用于将图片标记转换为链接
-	imgRegex := regexp.MustCompile(`!\[(.*?)\]\((.*?)\)`)
-
-	replaceOpFieldFunc := func(match, field string) string {
-		// 获取基础字段内容
-		var content string
-		basefield := strings.SplitN(field, "|", 2)[0]
-		switch basefield {
-		case "title":
-			content = item.Title
-		case "description":
-			if item.Description != "" {
-				// 将 HTML 转换为 Markdown
-				mdContent, err := converter.ConvertString(item.Description)
-				if err != nil {
-					log.Printf("Error converting HTML to Markdown: %v", err)
-					content = item.Description
-				} else {
-					// 将图片标记转换为链接
-					content = imgRegex.ReplaceAllString(mdContent, "[Media]($2)")
-				}
-			}
-		case "content":
-			if item.Content != "" {
-				// 将 HTML 转换为 Markdown
-				mdContent, err := converter.ConvertString(item.Content)
-				if err != nil {
-					log.Printf("Error converting HTML to Markdown: %v", err)
-					content = item.Content
-				} else {
-					// 将图片标记转换为链接
-					content = imgRegex.ReplaceAllString(mdContent, "[Media]($2)")
-				}
-			}
-		case "link":
-			content = item.Link
-		case "pubDate":
-			if item.PublishedParsed != nil {
-				content = item.PublishedParsed.Format("2006-01-02 15:04:05")
-			}
-		default:
-			return match
+// 生成频道的发送选项，按钮 url 使用模板字段渲染
+func (h *RssHandler) buildSendOptions(ctx *itemContext, feedConfig config.FeedConfig, channel string) *telegram.SendOptions {
+	sendOpts := channelSendOptions(feedConfig, channel)
+
+	// 按钮地址不属于消息内容，字段不按解析模式转义
+	plain := *ctx
+	plain.parseMode = config.ParseModeNone
+	for _, button := range feedConfig.Buttons {
+		link := h.renderMessage(feedConfig.TemplateEngine, &plain, button.URL)
+		// 字段为空或未能解析为有效链接时不显示该按钮
+		if u, err := url.Parse(link); err != nil || u.Scheme == "" || u.Host == "" {
+			log.Printf("Skipping button %q with invalid url %q: %s", button.Text, link, ctx.item.Title)
+			continue
 		}
+		sendOpts.Buttons = append(sendOpts.Buttons, telegram.Button{Text: button.Text, URL: link})
+	}
+	return sendOpts
+}
 
-		// 处理操作链
-		return processor.ProcessField(field, content)
+// recordButtons 返回发送选项中需要随消息记录保存的按钮
+func recordButtons(sendOpts *telegram.SendOptions) []storage.MessageButton {
+	var buttons []storage.MessageButton
+	for _, button := range sendOpts.Buttons {
+		buttons = append(buttons, storage.MessageButton{Text: button.Text, URL: button.URL})
 	}
+	return buttons
+}
 
-	// 使用正则表达式找出所有模板字段
-	fieldRegex := regexp.MustCompile(`{ (.*?) }`) //支持正则中使用花括号
-	message := fieldRegex.ReplaceAllStringFunc(template, func(match string) string {
-		// 去掉花括号
-		field := match[2 : len(match)-2]
+// 配置中的解析模式对应的 Telegram 解析模式
+var parseModes = map[string]string{
+	config.ParseModeMarkdown:   telegram.ParseModeMarkdown,
+	config.ParseModeMarkdownV2: telegram.ParseModeMarkdownV2,
+	config.ParseModeHTML:       telegram.ParseModeHTML,
+	config.ParseModeNone:       telegram.ParseModeNone,
+}
 
-		return replaceOpFieldFunc(match, field)
-	})
+// 生成频道的发送选项（不含按钮）
+func channelSendOptions(feedConfig config.FeedConfig, channel string) *telegram.SendOptions {
+	options := feedConfig.OptionsFor(channel)
+	return &telegram.SendOptions{
+		ParseMode:             parseModes[feedConfig.ParseMode],
+		DisableNotification:   options.DisableNotification != nil && *options.DisableNotification,
+		DisableWebPagePreview: options.DisableWebPagePreview != nil && *options.DisableWebPagePreview,
+		ProtectContent:        options.ProtectContent != nil && *options.ProtectContent,
+	}
+}
 
-	fieldRegex = regexp.MustCompile(`{([^}]+)}`) //正则中不使用花括号的情况
-	message = fieldRegex.ReplaceAllStringFunc(message, func(match string) string {
-		// 去掉花括号
-		field := match[1 : len(match)-1]
+// 指数退避+随机抖动
+func (h *RssHandler) ExponentialBackoffWithJitter(attempt int) {
+	base := time.Second
+	maxJitter := 500 * time.Millisecond                    // 最大抖动 500毫秒
+	delay := base * time.Duration(1<<attempt)              // 指数退避。1<<attempt表示attemp的2次幂
+	jitter := time.Duration(rand.Int63n(int64(maxJitter))) // 随机抖动
+	time.Sleep(delay + jitter)
+}
 
-		return replaceOpFieldFunc(match, field)
-	})
+// 格式化消息
+func (h *RssHandler) formatMessage(ctx *itemContext, template string) string {
+	return h.renderMessage(config.TemplateEngineBrace, ctx, template)
+}
 
-	// 清理多余的空行
+// 清理多余的空行
+func cleanMessage(message string) string {
 	message = strings.TrimSpace(message)
 	for strings.Contains(message, "\n\n\n") {
 		message = strings.ReplaceAll(message, "\n\n\n", "\n\n")
 	}
-
 	return message
 }
+
+// 创建 HTML 转 Markdown 的转换器
+func newMarkdownConverter() *md.Converter {
+	return md.NewConverter("", true, &md.Options{
+		EscapeMode: "disabled", // 禁用转义  包括针对|的转义
+	})
+}
@@ -0,0 +1,63 @@
+package rss
+
+import (
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSendOptions(t *testing.T) {
+	yes := true
+	h := NewRssHandler(&config.Config{}, nil, nil)
+	ctx := newItemContext("示例", nil, &gofeed.Item{
+		Title: "标题",
+		Link:  "https://example.com/a",
+	})
+
+	tests := []struct {
+		name    string
+		feed    config.FeedConfig
+		channel string
+		want    *telegram.SendOptions
+	}{
+		{
+			name: "feed 和频道选项",
+			feed: config.FeedConfig{
+				ParseMode:       config.ParseModeHTML,
+				DeliveryOptions: config.DeliveryOptions{DisableNotification: &yes},
+				ChannelOptions:  map[string]config.DeliveryOptions{"@a": {ProtectContent: &yes}},
+			},
+			channel: "@a",
+			want:    &telegram.SendOptions{ParseMode: telegram.ParseModeHTML, DisableNotification: true, ProtectContent: true},
+		},
+		{
+			name: "按钮链接使用模板字段",
+			feed: config.FeedConfig{
+				Buttons: []config.ButtonConfig{{Text: "阅读原文", URL: "{link}"}},
+			},
+			channel: "@a",
+			want:    &telegram.SendOptions{Buttons: []telegram.Button{{Text: "阅读原文", URL: "https://example.com/a"}}},
+		},
+		{
+			name: "跳过链接为空或无效的按钮",
+			feed: config.FeedConfig{
+				Buttons: []config.ButtonConfig{
+					{Text: "评论", URL: "{comments}"},
+					{Text: "相对路径", URL: "/a"},
+					{Text: "无效", URL: "not a url"},
+					{Text: "原文", URL: "{link}"},
+				},
+			},
+			channel: "@a",
+			want:    &telegram.SendOptions{Buttons: []telegram.Button{{Text: "原文", URL: "https://example.com/a"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, h.buildSendOptions(ctx, tt.feed, tt.channel))
+		})
+	}
+}
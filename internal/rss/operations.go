@@ -0,0 +1,207 @@
+package rss
+
+//文本处理类的模板操作符
+//对多值内容（如 extract-all 的结果）逐项处理
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 截断时默认添加的后缀
+const defaultTruncateSuffix = "…"
+
+var (
+	htmlTagRegex     = regexp.MustCompile(`<[^>]*>`)
+	markdownReplacer = strings.NewReplacer("_", `\_`, "*", `\*`, "`", "\\`", "[", `\[`)
+)
+
+// mapItems 对多值内容逐项处理，单值内容直接处理
+func mapItems(content string, fn func(string) string) string {
+	items := strings.Split(content, ExtractAllOperationGap)
+	for i, item := range items {
+		items[i] = fn(item)
+	}
+	return strings.Join(items, ExtractAllOperationGap)
+}
+
+// SuffixOperation 后缀操作，有内容时添加后缀
+type SuffixOperation struct{}
+
+func (op *SuffixOperation) Process(content string, suffix string) string {
+	if content == "" {
+		return ""
+	}
+	return mapItems(content, func(item string) string {
+		return item + suffix
+	})
+}
+
+// JoinOperation 使用指定分隔符连接多值内容，分隔符支持 \n
+type JoinOperation struct{}
+
+func (op *JoinOperation) Process(content string, sep string) string {
+	sep = strings.ReplaceAll(sep, `\n`, "\n")
+	return strings.ReplaceAll(content, ExtractAllOperationGap, sep)
+}
+
+// TruncateOperation 按字符数截断，参数格式 N[:后缀]，默认后缀为 …
+type TruncateOperation struct{}
+
+func (op *TruncateOperation) Process(content string, params string) string {
+	parts := strings.SplitN(params, ":", 2)
+	limit, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil || limit < 0 {
+		return content
+	}
+	suffix := defaultTruncateSuffix
+	if len(parts) == 2 {
+		suffix = parts[1]
+	}
+
+	return mapItems(content, func(item string) string {
+		if utf8.RuneCountInString(item) <= limit {
+			return item
+		}
+		return string([]rune(item)[:limit]) + suffix
+	})
+}
+
+func (op *TruncateOperation) Validate(params string) error {
+	return validateCount(strings.SplitN(params, ":", 2)[0])
+}
+
+// WordsOperation 保留前 N 个单词
+type WordsOperation struct{}
+
+func (op *WordsOperation) Process(content string, params string) string {
+	limit, err := strconv.Atoi(strings.TrimSpace(params))
+	if err != nil || limit < 0 {
+		return content
+	}
+	return mapItems(content, func(item string) string {
+		words := strings.Fields(item)
+		if len(words) <= limit {
+			return item
+		}
+		return strings.Join(words[:limit], " ") + defaultTruncateSuffix
+	})
+}
+
+func (op *WordsOperation) Validate(params string) error {
+	return validateCount(params)
+}
+
+// validateCount 检查参数为非负整数
+func validateCount(param string) error {
+	limit, err := strconv.Atoi(strings.TrimSpace(param))
+	if err != nil || limit < 0 {
+		return fmt.Errorf("invalid count %q", param)
+	}
+	return nil
+}
+
+// FirstLineOperation 保留第一个非空行
+type FirstLineOperation struct{}
+
+func (op *FirstLineOperation) Process(content string, params string) string {
+	return mapItems(content, func(item string) string {
+		for _, line := range strings.Split(item, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				return line
+			}
+		}
+		return ""
+	})
+}
+
+// UpperOperation 转换为大写
+type UpperOperation struct{}
+
+func (op *UpperOperation) Process(content string, params string) string {
+	return mapItems(content, strings.ToUpper)
+}
+
+// LowerOperation 转换为小写
+type LowerOperation struct{}
+
+func (op *LowerOperation) Process(content string, params string) string {
+	return mapItems(content, strings.ToLower)
+}
+
+// TrimOperation 去除首尾空白，指定参数时去除参数中的字符
+type TrimOperation struct{}
+
+func (op *TrimOperation) Process(content string, cutset string) string {
+	return mapItems(content, func(item string) string {
+		if cutset == "" {
+			return strings.TrimSpace(item)
+		}
+		return strings.Trim(item, cutset)
+	})
+}
+
+// StripHTMLOperation 去除 HTML 标签并还原实体字符
+type StripHTMLOperation struct{}
+
+func (op *StripHTMLOperation) Process(content string, params string) string {
+	return mapItems(content, func(item string) string {
+		return strings.TrimSpace(html.UnescapeString(htmlTagRegex.ReplaceAllString(item, "")))
+	})
+}
+
+// EscapeMarkdownOperation 转义 Markdown 特殊字符
+type EscapeMarkdownOperation struct{}
+
+func (op *EscapeMarkdownOperation) Process(content string, params string) string {
+	return mapItems(content, markdownReplacer.Replace)
+}
+
+// URLEncodeOperation URL 编码
+type URLEncodeOperation struct{}
+
+func (op *URLEncodeOperation) Process(content string, params string) string {
+	return mapItems(content, url.QueryEscape)
+}
+
+// HashtagOperation 转换为 Telegram 话题标签
+type HashtagOperation struct{}
+
+func (op *HashtagOperation) Process(content string, params string) string {
+	var tags []string
+	for _, item := range strings.Split(content, ExtractAllOperationGap) {
+		if tag := toHashtag(item); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, ExtractAllOperationGap)
+}
+
+// toHashtag 转换为有效的 Telegram 话题标签：只保留字母（包括中日韩文字）、数字和下划线
+// 全部为数字时添加下划线前缀，否则 Telegram 不会识别为标签
+func toHashtag(s string) string {
+	var b strings.Builder
+	hasNonDigit := false
+	for _, r := range strings.TrimPrefix(strings.TrimSpace(s), "#") {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsMark(r) || r == '_':
+			hasNonDigit = true
+			b.WriteRune(r)
+		case unicode.IsDigit(r):
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	if !hasNonDigit {
+		return "#_" + b.String()
+	}
+	return "#" + b.String()
+}
@@ -0,0 +1,81 @@
+package rss
+
+//消息解析模式
+//模板字段的内容按 feed 的 parse_mode 转换和转义，保证生成的消息能被 Telegram 正确解析：
+//markdown 与早期版本一致，description/content 转换为 Markdown，其他字段不转义；
+//markdownv2、html 和 none 中 description/content 转换为纯文本，markdownv2 和 html 中所有字段自动转义
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	md "github.com/JohannesKaufmann/html-to-markdown"
+)
+
+var (
+	// MarkdownV2 中需要转义的字符
+	markdownV2Replacer = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+		">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	// Telegram HTML 支持的实体
+	htmlReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	// 转换为纯文本时保留为换行的标签
+	htmlBreakRegex = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|li|h[1-6]|blockquote|pre|tr)\s*>`)
+)
+
+// markupFields 内容为 HTML 的字段，按解析模式转换格式，其他字段作为纯文本转义
+var markupFields = map[string]bool{
+	"description": true,
+	"content":     true,
+}
+
+// legacyMarkdown 是否为 markdown 解析模式（默认），该模式下字段内容不转义，escape-md 操作符有效
+func legacyMarkdown(parseMode string) bool {
+	return parseMode == "" || parseMode == config.ParseModeMarkdown
+}
+
+// escapeText 按解析模式转义纯文本
+func escapeText(parseMode, text string) string {
+	switch parseMode {
+	case config.ParseModeMarkdownV2:
+		return markdownV2Replacer.Replace(text)
+	case config.ParseModeHTML:
+		return htmlReplacer.Replace(text)
+	}
+	return text
+}
+
+// boldText 按解析模式生成粗体文本，text 需已转义
+func boldText(parseMode, text string) string {
+	switch parseMode {
+	case config.ParseModeHTML:
+		return "<b>" + text + "</b>"
+	case config.ParseModeNone:
+		return text
+	}
+	return "*" + text + "*"
+}
+
+// convertHTML 将 HTML 内容转换为解析模式对应的格式
+// markdown 模式使用原有的 Markdown 转换，其他模式转换为纯文本后按解析模式转义
+func convertHTML(parseMode, content string, converter *md.Converter) string {
+	if legacyMarkdown(parseMode) {
+		return htmlToMarkdown(content, converter)
+	}
+	return escapeText(parseMode, htmlToText(content))
+}
+
+// htmlToText 去除 HTML 标签并还原实体字符，与浏览器一样合并空白字符，<br> 和块级元素的结束转换为换行
+func htmlToText(content string) string {
+	text := strings.Join(strings.Fields(content), " ")
+	text = htmlBreakRegex.ReplaceAllString(text, "\n")
+	text = html.UnescapeString(htmlTagRegex.ReplaceAllString(text, ""))
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return cleanMessage(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,122 @@
+package rss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderParseMode(t *testing.T) {
+	published := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	item := &gofeed.Item{
+		Title:           "A <b> & C_D-1.0!",
+		Link:            "https://example.com/a_(1)?x=1&y=2",
+		Description:     `<p>Hello <b>world</b> &amp; <a href="https://example.com/b">link</a></p><p><img src="https://example.com/c.png"> <code>a_b</code></p>`,
+		Categories:      []string{"Go", "C++"},
+		PublishedParsed: &published,
+	}
+
+	tests := []struct {
+		name      string
+		parseMode string
+		engine    string
+		template  string
+		want      string
+	}{
+		{
+			name:     "markdown 与原有行为一致",
+			template: "*{title}*\n{description}\n[原文]({link})",
+			want:     "*A <b> & C_D-1.0!*\nHello **world** & [link](https://example.com/b)\n\n[Media](https://example.com/c.png)`a_b`\n[原文](https://example.com/a_(1)?x=1&y=2)",
+		},
+		{
+			name:      "markdown escape-md",
+			parseMode: config.ParseModeMarkdown,
+			template:  "{title|escape-md}",
+			want:      "A <b> & C\\_D-1.0!",
+		},
+		{
+			name:      "markdownv2",
+			parseMode: config.ParseModeMarkdownV2,
+			template:  "*{title}*\n{description}\n[原文]({link})",
+			want:      "*A <b\\> & C\\_D\\-1\\.0\\!*\nHello world & link\na\\_b\n[原文](https://example\\.com/a\\_\\(1\\)?x\\=1&y\\=2)",
+		},
+		{
+			name:      "markdownv2 不重复转义",
+			parseMode: config.ParseModeMarkdownV2,
+			template:  "{title|escape-md} {categories|prefix:#} {pubDate|date:2006-01-02}",
+			want:      "A <b\\> & C\\_D\\-1\\.0\\! \\#Go  \\#C\\+\\+ 2024\\-01\\-02",
+		},
+		{
+			name:      "html",
+			parseMode: config.ParseModeHTML,
+			template:  "<b>{title}</b>\n{description}\n<a href=\"{link}\">原文</a>",
+			want:      "<b>A &lt;b&gt; &amp; C_D-1.0!</b>\nHello world &amp; link\na_b\n<a href=\"https://example.com/a_(1)?x=1&amp;y=2\">原文</a>",
+		},
+		{
+			name:      "none",
+			parseMode: config.ParseModeNone,
+			template:  "{title}\n{description}\n{link}",
+			want:      "A <b> & C_D-1.0!\nHello world & link\na_b\nhttps://example.com/a_(1)?x=1&y=2",
+		},
+		{
+			name:      "Go 模板 markdownv2",
+			parseMode: config.ParseModeMarkdownV2,
+			engine:    config.TemplateEngineGo,
+			template:  `*{{.Title}}* {{index .Categories 1}} {{.Published | date "2006-01-02"}} {{.Title | escapeMd}} {{field "feedName"}}`,
+			want:      "*A <b\\> & C\\_D\\-1\\.0\\!* C\\+\\+ 2024\\-01\\-02 A <b\\> & C\\_D\\-1\\.0\\! a\\.b",
+		},
+		{
+			name:      "Go 模板 html",
+			parseMode: config.ParseModeHTML,
+			engine:    config.TemplateEngineGo,
+			template:  `<b>{{.Title}}</b> <a href="{{.Link}}">{{.Feed.Name}}</a>`,
+			want:      `<b>A &lt;b&gt; &amp; C_D-1.0!</b> <a href="https://example.com/a_(1)?x=1&amp;y=2">a.b</a>`,
+		},
+	}
+
+	handler := &RssHandler{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newItemContext("a.b", nil, item)
+			ctx.parseMode = tt.parseMode
+			assert.Equal(t, tt.want, handler.renderMessage(tt.engine, ctx, tt.template))
+		})
+	}
+}
+
+func TestBuildSendOptionsButtonNotEscaped(t *testing.T) {
+	handler := &RssHandler{}
+	feedConfig := config.FeedConfig{
+		ParseMode: config.ParseModeMarkdownV2,
+		Buttons:   []config.ButtonConfig{{Text: "原文", URL: "{link}"}},
+	}
+	ctx := handler.contextFor(feedConfig, nil, &gofeed.Item{Link: "https://example.com/a-b.html"})
+
+	sendOpts := handler.buildSendOptions(ctx, feedConfig, "")
+	if assert.Len(t, sendOpts.Buttons, 1) {
+		assert.Equal(t, "https://example.com/a-b.html", sendOpts.Buttons[0].URL)
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", ""},
+		{"entities", "a &amp; b &lt;c&gt; &#39;d&#39;", "a & b <c> 'd'"},
+		{"line breaks", "a<br>b<BR/>c<br />d", "a\nb\nc\nd"},
+		{"blocks", "<h1>标题</h1><p>第一段\n  续行</p><ul><li>a</li><li>b</li></ul>", "标题\n第一段 续行\na\nb"},
+		{"blank lines", "<p>a</p><br><br><br><p>b</p>", "a\n\nb"},
+		{"inline", `<p>Hello <b>world</b> <a href="https://example.com">link</a></p>`, "Hello world link"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, htmlToText(tt.content))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package rss
+
+//模板预览
+//解析 feed 并按模板渲染最新的文章，不读写存储、不发送消息
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/mmcdole/gofeed"
+)
+
+// PreviewMessage 单篇文章的预览结果
+type PreviewMessage struct {
+	Title   string
+	Message string
+	Buttons []telegram.Button
+}
+
+// Preview 解析 feedConfig.URL（URL 或本地文件）并渲染最新的 limit 篇文章，limit <= 0 时渲染全部文章
+// 渲染结果与推送时相同，按发布时间从新到旧排列
+func (h *RssHandler) Preview(feedConfig config.FeedConfig, limit int) ([]PreviewMessage, error) {
+	feed, err := h.parseSource(feedConfig.URL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing feed %s: %w", feedConfig.Name, err)
+	}
+
+	var messages []PreviewMessage
+	for _, item := range latestItems(feed.Items, limit) {
+		ctx := h.contextFor(feedConfig, feed, item)
+		sendOpts := h.buildSendOptions(ctx, feedConfig, "")
+		messages = append(messages, PreviewMessage{
+			Title:   item.Title,
+			Message: h.renderMessage(feedConfig.TemplateEngine, ctx, feedConfig.Template),
+			Buttons: sendOpts.Buttons,
+		})
+	}
+	return messages, nil
+}
+
+// parseSource 解析 http(s) 地址或本地文件
+func (h *RssHandler) parseSource(source string) (*gofeed.Feed, error) {
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		return h.parser.ParseURL(source)
+	}
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return h.parser.Parse(file)
+}
+
+// latestItems 按发布时间从新到旧返回前 limit 篇文章，没有发布时间的文章保持原顺序排在最后
+func latestItems(items []*gofeed.Item, limit int) []*gofeed.Item {
+	sorted := make([]*gofeed.Item, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].PublishedParsed, sorted[j].PublishedParsed
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.After(*b)
+	})
+
+	if limit > 0 && len(sorted) > limit {
+		sorted = sorted[:limit]
+	}
+	return sorted
+}
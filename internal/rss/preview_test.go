@@ -0,0 +1,46 @@
+package rss
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreview(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>示例</title>
+<item><title>无时间</title><link>https://example.com/3</link></item>
+<item><title>旧 5折</title><link>https://example.com/1</link><pubDate>Mon, 09 Dec 2024 08:00:00 GMT</pubDate></item>
+<item><title>新 8折</title><link>https://example.com/2</link><pubDate>Tue, 10 Dec 2024 08:00:00 GMT</pubDate></item>
+</channel></rss>`
+	path := filepath.Join(t.TempDir(), "feed.xml")
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	// 预览不使用存储和机器人
+	handler := NewRssHandler(&config.Config{}, nil, nil)
+	feedConfig := config.FeedConfig{
+		Name:     "示例",
+		URL:      path,
+		Template: "{title|extract:(\\d+)折|default:无} {feedTitle}",
+		Buttons:  []config.ButtonConfig{{Text: "阅读原文", URL: "{link}"}},
+	}
+
+	messages, err := handler.Preview(feedConfig, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []PreviewMessage{
+		{Title: "新 8折", Message: "8 示例", Buttons: []telegram.Button{{Text: "阅读原文", URL: "https://example.com/2"}}},
+		{Title: "旧 5折", Message: "5 示例", Buttons: []telegram.Button{{Text: "阅读原文", URL: "https://example.com/1"}}},
+	}, messages)
+
+	messages, err = handler.Preview(feedConfig, 0)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 3)
+	assert.Equal(t, "无 示例", messages[2].Message)
+
+	_, err = handler.Preview(config.FeedConfig{Name: "missing", URL: filepath.Join(t.TempDir(), "missing.xml")}, 1)
+	assert.Error(t, err)
+}
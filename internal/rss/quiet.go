@@ -0,0 +1,87 @@
+package rss
+
+//频道免打扰时段
+//queue 模式下暂存文章，时段结束后按顺序推送；silent 模式下静默推送
+
+import (
+	"log"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+)
+
+// quietModeAt 返回频道在 now 时刻的免打扰处理方式，不在免打扰时段时返回空字符串
+func quietModeAt(feedConfig config.FeedConfig, channel string, now time.Time) string {
+	quietHours := feedConfig.OptionsFor(channel).QuietHours
+	if !quietHours.Active(now) {
+		return ""
+	}
+	return quietHours.EffectiveMode()
+}
+
+// holdItem 暂存免打扰时段的文章
+func (h *RssHandler) holdItem(feedConfig config.FeedConfig, channel, itemID string, feed *gofeed.Feed, item *gofeed.Item) {
+	if err := h.storage.HoldItem(feedConfig.URL, channel, itemID, feedInfo(feed), item); err != nil {
+		log.Printf("HoldItem ERROR!!  channel %s: %v", channel, err)
+		return
+	}
+	// 已持久化到暂存队列，标记为已处理，避免重复暂存
+	if err := h.storage.MarkItemSeen(feedConfig.URL, feedConfig.Name, channel, itemID); err != nil {
+		log.Printf("MarkItemSeen ERROR!!  channel %s: %v", channel, err)
+	}
+	log.Printf("Quiet hours, item held for channel %s: %s", channel, item.Title)
+}
+
+// releaseHeld 免打扰时段结束后按暂存顺序推送文章
+func (h *RssHandler) releaseHeld(feedConfig config.FeedConfig) {
+	now := time.Now()
+	for _, channel := range feedConfig.Channels {
+		held := h.storage.HeldItems(feedConfig.URL, channel)
+		if len(held) == 0 || quietModeAt(feedConfig, channel, now) == config.QuietModeQueue {
+			continue
+		}
+
+		for _, queued := range held {
+			ctx := h.contextFor(feedConfig, queued.Feed, queued.Item)
+			message := h.renderMessage(feedConfig.TemplateEngine, ctx, feedConfig.Template)
+			if message != "" {
+				sendOpts := h.buildSendOptions(ctx, feedConfig, channel)
+				if !h.deliverItem(feedConfig, channel, queued.ItemID, queued.Item, message, sendOpts) {
+					// 保持顺序，剩余文章下次检查时重试
+					break
+				}
+			}
+			if err := h.storage.ReleaseHeld(feedConfig.URL, channel, queued.ItemID); err != nil {
+				log.Printf("ReleaseHeld ERROR!!  channel %s: %v", channel, err)
+				break
+			}
+		}
+	}
+}
+
+// dropRemovedHeld 删除已从配置中移除的 feed 或频道中暂存的文章
+func (h *RssHandler) dropRemovedHeld(cfg *config.Config) {
+	if h.storage == nil {
+		return
+	}
+	channels := make(map[string]map[string]bool) // feedURL -> channel
+	for _, feedConfig := range cfg.Feeds {
+		if channels[feedConfig.URL] == nil {
+			channels[feedConfig.URL] = make(map[string]bool)
+		}
+		for _, channel := range feedConfig.Channels {
+			channels[feedConfig.URL][channel] = true
+		}
+	}
+
+	dropped, err := h.storage.DropHeld(func(feedURL, channel string) bool {
+		return channels[feedURL][channel]
+	})
+	if err != nil {
+		log.Printf("DropHeld ERROR!!  %v", err)
+	}
+	if dropped > 0 {
+		log.Printf("Dropped %d held items for feeds or channels removed from config", dropped)
+	}
+}
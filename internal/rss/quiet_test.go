@@ -0,0 +1,127 @@
+package rss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/storage"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+// quietHours 返回从当前时间前 1 小时开始（active 为 false 时从 1 小时后开始）的免打扰时段
+func quietHours(active bool, mode string) *config.QuietHoursConfig {
+	start := time.Now().UTC().Add(-time.Hour)
+	if !active {
+		start = start.Add(2 * time.Hour)
+	}
+	return &config.QuietHoursConfig{
+		Start:    start.Format("15:04"),
+		End:      start.Add(2 * time.Hour).Format("15:04"),
+		Timezone: "UTC",
+		Mode:     mode,
+	}
+}
+
+func newQuietTestHandler(t *testing.T) (*RssHandler, *fakeBot, *storage.Storage) {
+	store, err := storage.NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	bot := &fakeBot{}
+	handler := NewRssHandler(&config.Config{}, bot, store)
+	// 不等待发送间隔
+	handler.SetDryRun(true)
+	return handler, bot, store
+}
+
+func TestDeliverItemsQuietHours(t *testing.T) {
+	feed := &gofeed.Feed{Items: []*gofeed.Item{
+		{Title: "A", Link: "https://example.com/a"},
+		{Title: "B", Link: "https://example.com/b"},
+	}}
+
+	t.Run("queue", func(t *testing.T) {
+		handler, bot, store := newQuietTestHandler(t)
+		feedConfig := config.FeedConfig{
+			URL:             "https://example.com/rss.xml",
+			Channels:        []string{"@a", "@b"},
+			Template:        "{title}",
+			DeliveryOptions: config.DeliveryOptions{QuietHours: quietHours(true, config.QuietModeQueue)},
+			ChannelOptions: map[string]config.DeliveryOptions{
+				"@b": {QuietHours: quietHours(false, config.QuietModeQueue)},
+			},
+		}
+		assert.Equal(t, 0, handler.deliverItems(feedConfig, feed, feed.Items))
+
+		// @a 暂存，@b 不在免打扰时段照常推送
+		assert.Len(t, store.HeldItems(feedConfig.URL, "@a"), 2)
+		assert.Empty(t, store.HeldItems(feedConfig.URL, "@b"))
+		assert.Len(t, bot.calls, 2)
+		for _, call := range bot.calls {
+			assert.Equal(t, "@b", call.channel)
+			assert.False(t, call.opts.DisableNotification)
+		}
+	})
+
+	t.Run("silent", func(t *testing.T) {
+		handler, bot, store := newQuietTestHandler(t)
+		feedConfig := config.FeedConfig{
+			URL:             "https://example.com/rss.xml",
+			Channels:        []string{"@a"},
+			Template:        "{title}",
+			DeliveryOptions: config.DeliveryOptions{QuietHours: quietHours(true, config.QuietModeSilent)},
+		}
+		assert.Equal(t, 0, handler.deliverItems(feedConfig, feed, feed.Items))
+
+		// 照常推送，但不触发通知
+		assert.Empty(t, store.HeldItems(feedConfig.URL, "@a"))
+		assert.Len(t, bot.calls, 2)
+		for _, call := range bot.calls {
+			assert.True(t, call.opts.DisableNotification)
+		}
+	})
+}
+
+func TestReleaseHeld(t *testing.T) {
+	handler, bot, store := newQuietTestHandler(t)
+	feedConfig := config.FeedConfig{
+		URL:             "https://example.com/rss.xml",
+		Channels:        []string{"@a"},
+		Template:        "{title}",
+		DeliveryOptions: config.DeliveryOptions{QuietHours: quietHours(true, config.QuietModeQueue)},
+	}
+	for _, title := range []string{"C", "A", "B"} {
+		item := &gofeed.Item{Title: title, Link: "https://example.com/" + title}
+		assert.NoError(t, store.HoldItem(feedConfig.URL, "@a", generateItemID(item), nil, item))
+	}
+
+	// 免打扰时段内不推送
+	handler.releaseHeld(feedConfig)
+	assert.Empty(t, bot.calls)
+
+	// 时段结束后按暂存顺序推送
+	feedConfig.QuietHours = quietHours(false, config.QuietModeQueue)
+	handler.releaseHeld(feedConfig)
+	var texts []string
+	for _, call := range bot.calls {
+		texts = append(texts, call.text)
+	}
+	assert.Equal(t, []string{"C", "A", "B"}, texts)
+	assert.Empty(t, store.HeldItems(feedConfig.URL, "@a"))
+}
+
+func TestUpdateConfigDropsRemovedHeld(t *testing.T) {
+	handler, _, store := newQuietTestHandler(t)
+	item := &gofeed.Item{Title: "A", Link: "https://example.com/a"}
+	for _, channel := range []string{"@a", "@b"} {
+		assert.NoError(t, store.HoldItem("https://example.com/rss.xml", channel, generateItemID(item), nil, item))
+	}
+	assert.NoError(t, store.HoldItem("https://example.com/removed.xml", "@a", generateItemID(item), nil, item))
+
+	handler.UpdateConfig(&config.Config{Feeds: []config.FeedConfig{
+		{URL: "https://example.com/rss.xml", Channels: []string{"@a"}},
+	}})
+	assert.Len(t, store.HeldItems("https://example.com/rss.xml", "@a"), 1)
+	assert.Empty(t, store.HeldItems("https://example.com/rss.xml", "@b"))
+	assert.Empty(t, store.HeldItems("https://example.com/removed.xml", "@a"))
+}
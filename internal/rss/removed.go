@@ -0,0 +1,88 @@
+package rss
+
+//已推送文章从feed中移除后的处理
+//文章连续多次抓取不在feed中时，根据 feed 的 on_removed 配置删除或标记消息
+
+import (
+	"log"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/storage"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/mmcdole/gofeed"
+)
+
+// expiredMarkerPrefix 已失效消息的前缀
+func expiredMarkerPrefix(parseMode string) string {
+	return "⚠️ " + boldText(parseMode, "已失效") + "\n\n"
+}
+
+// processRemoved 统计已推送文章的连续缺失次数，达到阈值后按配置处理
+// 调用方需保证本次抓取到的 items 非空，避免 feed 临时异常时误删消息
+func (h *RssHandler) processRemoved(feedConfig config.FeedConfig, items []*gofeed.Item) {
+	if feedConfig.OnRemoved != config.OnRemovedDelete && feedConfig.OnRemoved != config.OnRemovedExpire {
+		return
+	}
+	if len(items) == 0 {
+		return
+	}
+
+	removedAfter := feedConfig.RemovedAfter
+	if removedAfter <= 0 {
+		removedAfter = config.DefaultRemovedAfter
+	}
+
+	present := make(map[string]bool, len(items))
+	for _, item := range items {
+		present[generateItemID(item)] = true
+	}
+
+	for _, channel := range feedConfig.Channels {
+		updates := make(map[string]*storage.MessageRecord)
+
+		for itemID, record := range h.storage.ListMessages(feedConfig.URL, channel) {
+			record := record
+			if present[itemID] {
+				// 文章重新出现，重置计数
+				if record.Missing > 0 {
+					record.Missing = 0
+					updates[itemID] = &record
+				}
+				continue
+			}
+
+			record.Missing++
+			if record.Missing < removedAfter {
+				updates[itemID] = &record
+				continue
+			}
+
+			var err error
+			switch feedConfig.OnRemoved {
+			case config.OnRemovedDelete:
+				err = h.bot.Delete(channel, record.MessageID)
+			case config.OnRemovedExpire:
+				// 编辑时使用原消息的发送选项和按钮，否则按钮会被移除
+				sendOpts := channelSendOptions(feedConfig, channel)
+				for _, button := range record.Buttons {
+					sendOpts.Buttons = append(sendOpts.Buttons, telegram.Button{Text: button.Text, URL: button.URL})
+				}
+				err = h.bot.Edit(channel, record.MessageID, expiredMarkerPrefix(feedConfig.ParseMode)+record.Text, sendOpts)
+			}
+			if err != nil {
+				// 保留记录，下次检查时重试
+				log.Printf("Error applying removal (%s) to message %d in channel %s: %v", feedConfig.OnRemoved, record.MessageID, channel, err)
+				updates[itemID] = &record
+				continue
+			}
+			log.Printf("Applied removal (%s) to message %d in channel %s", feedConfig.OnRemoved, record.MessageID, channel)
+
+			// 处理完成后不再跟踪该消息
+			updates[itemID] = nil
+		}
+
+		if err := h.storage.UpdateMessages(feedConfig.URL, channel, updates); err != nil {
+			log.Printf("UpdateMessages ERROR!!  channel %s: %v", channel, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package rss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/storage"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessRemovedExpire(t *testing.T) {
+	const feedURL = "https://example.com/rss.xml"
+	yes := true
+	store, err := storage.NewStorage(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, store.SaveMessage(feedURL, "@a", "removed", storage.MessageRecord{
+		MessageID: 10,
+		Text:      "A",
+		SentAt:    time.Now(),
+		Buttons:   []storage.MessageButton{{Text: "原文", URL: "https://example.com/a"}},
+	}))
+
+	bot := &fakeBot{}
+	handler := NewRssHandler(&config.Config{}, bot, store)
+	feedConfig := config.FeedConfig{
+		URL:             feedURL,
+		Channels:        []string{"@a"},
+		ParseMode:       config.ParseModeMarkdown,
+		OnRemoved:       config.OnRemovedExpire,
+		RemovedAfter:    2,
+		DeliveryOptions: config.DeliveryOptions{DisableWebPagePreview: &yes},
+	}
+	items := []*gofeed.Item{{Title: "B", Link: "https://example.com/b"}}
+
+	// 未达到连续缺失次数时只记录次数
+	handler.processRemoved(feedConfig, items)
+	assert.Empty(t, bot.calls)
+	record, _ := store.GetMessage(feedURL, "@a", "removed")
+	assert.Equal(t, 1, record.Missing)
+
+	// 编辑消息时保留发送选项和按钮
+	handler.processRemoved(feedConfig, items)
+	assert.Equal(t, []botCall{{
+		action:    "edit",
+		channel:   "@a",
+		messageID: 10,
+		text:      expiredMarkerPrefix("") + "A",
+		opts: &telegram.SendOptions{
+			ParseMode:             telegram.ParseModeMarkdown,
+			DisableWebPagePreview: true,
+			Buttons:               []telegram.Button{{Text: "原文", URL: "https://example.com/a"}},
+		},
+	}}, bot.calls)
+	_, exists := store.GetMessage(feedURL, "@a", "removed")
+	assert.False(t, exists)
+}
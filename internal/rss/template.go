@@ -7,6 +7,7 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Operation 定义模板操作接口
@@ -14,6 +15,39 @@ type Operation interface {
 	Process(content string, params string) string
 }
 
+// TimeOperation 可直接处理时间值的操作，用于 pubDate 等时间字段
+type TimeOperation interface {
+	Operation
+	// ProcessTime 处理时间值，返回的时间非 nil 时继续作为时间值传给下一个操作
+	ProcessTime(t time.Time, params string) (*time.Time, string)
+}
+
+// ValidatingOperation 可校验参数的操作，用于配置加载时检查模板
+type ValidatingOperation interface {
+	Operation
+	Validate(params string) error
+}
+
+// CompilableOperation 可预先处理参数的操作，例如编译正则表达式
+// 返回的操作忽略 Process 的 params 参数
+type CompilableOperation interface {
+	Operation
+	Compile(params string) (Operation, error)
+}
+
+// compiledOperation 参数已预先处理的操作
+type compiledOperation func(content string) string
+
+func (f compiledOperation) Process(content string, _ string) string {
+	return f(content)
+}
+
+// operationStep 操作链中已解析的一个操作
+type operationStep struct {
+	op     Operation
+	params string
+}
+
 // OperationRegistry 操作注册表
 type OperationRegistry struct {
 	operations map[string]Operation
@@ -36,6 +70,21 @@ func NewTemplateProcessor() *TemplateProcessor {
 	registry.Register("replace", &ReplaceOperation{})
 	registry.Register("default", &DefaultOperation{})
 	registry.Register("prefix", &PrefixOperation{})
+	registry.Register("suffix", &SuffixOperation{})
+	registry.Register("join", &JoinOperation{})
+	registry.Register("truncate", &TruncateOperation{})
+	registry.Register("words", &WordsOperation{})
+	registry.Register("first-line", &FirstLineOperation{})
+	registry.Register("upper", &UpperOperation{})
+	registry.Register("lower", &LowerOperation{})
+	registry.Register("trim", &TrimOperation{})
+	registry.Register("strip-html", &StripHTMLOperation{})
+	registry.Register("escape-md", &EscapeMarkdownOperation{})
+	registry.Register("urlencode", &URLEncodeOperation{})
+	registry.Register("hashtag", &HashtagOperation{})
+	registry.Register("date", &DateOperation{})
+	registry.Register("tz", &TimezoneOperation{})
+	registry.Register("ago", &AgoOperation{})
 
 	return &TemplateProcessor{registry: registry}
 }
@@ -55,6 +104,19 @@ func (op *ExtractOperation) Process(content string, params string) string {
 		log.Printf("Invalid regex pattern: %v", err)
 		return content
 	}
+	return extractFirst(re, content)
+}
+
+func (op *ExtractOperation) Compile(params string) (Operation, error) {
+	re, err := regexp.Compile(params)
+	if err != nil {
+		return nil, err
+	}
+	return compiledOperation(func(content string) string { return extractFirst(re, content) }), nil
+}
+
+// extractFirst 返回第一个匹配
+func extractFirst(re *regexp.Regexp, content string) string {
 	matches := re.FindStringSubmatch(content)
 	if len(matches) > 1 {
 		// 如果有捕获组，返回第一个捕获组
@@ -82,7 +144,19 @@ func (op *ExtractAllOperation) Process(content string, params string) string {
 		log.Printf("Invalid regex pattern: %v", err)
 		return content
 	}
+	return extractAll(re, content)
+}
+
+func (op *ExtractAllOperation) Compile(params string) (Operation, error) {
+	re, err := regexp.Compile(params)
+	if err != nil {
+		return nil, err
+	}
+	return compiledOperation(func(content string) string { return extractAll(re, content) }), nil
+}
 
+// extractAll 返回所有匹配，使用 ExtractAllOperationGap 连接
+func extractAll(re *regexp.Regexp, content string) string {
 	// 提取所有匹配结果
 	var allMatches []string
 
@@ -145,6 +219,19 @@ func (op *ReplaceOperation) Process(content string, params string) string {
 	return result
 }
 
+func (op *ReplaceOperation) Compile(params string) (Operation, error) {
+	parts := strings.SplitN(params, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("replace requires pattern:replacement")
+	}
+	re, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	replacement := parts[1]
+	return compiledOperation(func(content string) string { return re.ReplaceAllString(content, replacement) }), nil
+}
+
 // DefaultOperation 默认值操作
 type DefaultOperation struct{}
 
@@ -157,30 +244,97 @@ func (op *DefaultOperation) Process(content string, defaultValue string) string
 
 // ProcessField 处理模板字段
 func (p *TemplateProcessor) ProcessField(field, content string) string {
+	steps, _ := p.compileOperations(field, false)
+	return applyOperations(steps, content)
+}
+
+// ProcessTimeField 处理时间类型的模板字段
+// 时间操作直接处理时间值，遇到其他操作时先按 layout 格式化为文本
+func (p *TemplateProcessor) ProcessTimeField(field string, t time.Time, layout string) string {
+	steps, _ := p.compileOperations(field, false)
+	return applyTimeOperations(steps, t, layout)
+}
+
+// compileOperations 解析字段中的操作链，可预编译的操作预先处理参数
+// strict 为 true 时不支持的操作符和无效的参数返回错误，否则忽略不支持的操作符，参数无效的操作在执行时记录日志
+func (p *TemplateProcessor) compileOperations(field string, strict bool) ([]operationStep, error) {
 	operations := splitEscaped(field, '|')
-	if len(operations) == 1 {
-		return content
-	}
 
-	result := content
+	var steps []operationStep
 	// 第一个是字段名，从第二个开始是操作
 	for _, op := range operations[1:] {
-		parts := strings.SplitN(op, ":", 2)
-		if len(parts) < 2 {
+		opName, params := parseOperation(op)
+		operation, exists := p.registry.operations[opName]
+		if !exists {
+			if strict {
+				return nil, fmt.Errorf("unknown operator %q", opName)
+			}
 			continue
 		}
 
-		opName := strings.TrimSpace(parts[0])
-		params := parts[1] // 保留原始空格，因为在正则表达式中可能有意义
+		switch v := operation.(type) {
+		case CompilableOperation:
+			compiled, err := v.Compile(params)
+			if err != nil {
+				if strict {
+					return nil, fmt.Errorf("%s: %w", opName, err)
+				}
+				break
+			}
+			operation, params = compiled, ""
+		case ValidatingOperation:
+			if err := v.Validate(params); err != nil && strict {
+				return nil, fmt.Errorf("%s: %w", opName, err)
+			}
+		}
+		steps = append(steps, operationStep{op: operation, params: params})
+	}
+	return steps, nil
+}
+
+// applyOperations 依次执行操作链
+func applyOperations(steps []operationStep, content string) string {
+	result := content
+	for _, step := range steps {
+		result = step.op.Process(result, step.params)
+	}
+	return result
+}
 
-		if operation, exists := p.registry.operations[opName]; exists {
-			result = operation.Process(result, params)
+// applyTimeOperations 对时间值依次执行操作链
+func applyTimeOperations(steps []operationStep, t time.Time, layout string) string {
+	value := &t
+	var result string
+	for _, step := range steps {
+		if value != nil {
+			if timeOp, ok := step.op.(TimeOperation); ok {
+				value, result = timeOp.ProcessTime(*value, step.params)
+				continue
+			}
+			result = value.Format(layout)
+			value = nil
 		}
+		result = step.op.Process(result, step.params)
 	}
 
+	if value != nil {
+		return value.Format(layout)
+	}
 	return result
 }
 
+// parseOperation 解析操作名称和参数
+func parseOperation(op string) (string, string) {
+	parts := strings.SplitN(op, ":", 2)
+
+	opName := strings.TrimSpace(parts[0])
+	params := "" // 部分操作符没有参数，例如 upper
+	if len(parts) == 2 {
+		params = parts[1] // 保留原始空格，因为在正则表达式中可能有意义
+	}
+	return opName, params
+}
+
 // splitEscaped 分割字符串，处理转义字符, 之后还原转义字符
 func splitEscaped(s string, sep byte) []string {
 	interSymbol := fmt.Sprintf("<===%%%X%%===>", sep)
@@ -81,6 +81,139 @@ func TestTemplateProcessor_ProcessField(t *testing.T) {
 	}
 }
 
+func TestTemplateProcessor_TextOperations(t *testing.T) {
+	processor := NewTemplateProcessor()
+
+	tests := []struct {
+		name     string
+		field    string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Truncate with default suffix",
+			field:    "title|truncate:4",
+			content:  "这是一个很长的标题",
+			expected: "这是一个…",
+		},
+		{
+			name:     "Truncate with custom suffix",
+			field:    "title|truncate:5:...",
+			content:  "Hello World",
+			expected: "Hello...",
+		},
+		{
+			name:     "Truncate short content",
+			field:    "title|truncate:20",
+			content:  "短标题",
+			expected: "短标题",
+		},
+		{
+			name:     "Upper",
+			field:    "title|upper",
+			content:  "Hello 世界",
+			expected: "HELLO 世界",
+		},
+		{
+			name:     "Lower",
+			field:    "title|lower",
+			content:  "Hello World",
+			expected: "hello world",
+		},
+		{
+			name:     "Trim whitespace",
+			field:    "title|trim",
+			content:  "  标题 \n",
+			expected: "标题",
+		},
+		{
+			name:     "Trim cutset",
+			field:    "title|trim:【】",
+			content:  "【公告】",
+			expected: "公告",
+		},
+		{
+			name:     "Strip html",
+			field:    "description|strip-html",
+			content:  "<p>价格 &amp; <b>优惠</b></p>",
+			expected: "价格 & 优惠",
+		},
+		{
+			name:     "First line",
+			field:    "description|first-line",
+			content:  "\n  第一行\n第二行",
+			expected: "第一行",
+		},
+		{
+			name:     "Words",
+			field:    "description|words:3",
+			content:  "the quick brown fox jumps",
+			expected: "the quick brown…",
+		},
+		{
+			name:     "Suffix",
+			field:    "title|extract:(\\d+)折|suffix:折优惠",
+			content:  "全场8折",
+			expected: "8折优惠",
+		},
+		{
+			name:     "Suffix on empty content",
+			field:    "title|extract:(\\d+)折|suffix:折优惠",
+			content:  "没有折扣",
+			expected: "",
+		},
+		{
+			name:     "Join extract-all results",
+			field:    "title|extract-all:(\\d+)折|join: / ",
+			content:  "8折 或 5折",
+			expected: "8 / 5",
+		},
+		{
+			name:     "Join with newline",
+			field:    "title|extract-all:(\\d+)折|suffix:折|join:\\n",
+			content:  "8折 或 5折",
+			expected: "8折\n5折",
+		},
+		{
+			name:     "Escape markdown",
+			field:    "title|escape-md",
+			content:  "snake_case *bold* [link] `code`",
+			expected: "snake\\_case \\*bold\\* \\[link] \\`code\\`",
+		},
+		{
+			name:     "URL encode",
+			field:    "title|urlencode",
+			content:  "a b&c=中",
+			expected: "a+b%26c%3D%E4%B8%AD",
+		},
+		{
+			name:     "Hashtag",
+			field:    "title|hashtag",
+			content:  "Hello World! 你好-世界",
+			expected: "#HelloWorld你好世界",
+		},
+		{
+			name:     "Hashtag with digits only",
+			field:    "title|hashtag",
+			content:  "2024",
+			expected: "#_2024",
+		},
+		{
+			name:     "Hashtag for extract-all results",
+			field:    "title|extract-all:【(.*?)】|hashtag|join: ",
+			content:  "【Apple 发布会】【iPhone 16】【.】",
+			expected: "#Apple发布会 #iPhone16",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := processor.ProcessField(tt.field, tt.content)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestFormatMessage(t *testing.T) {
 	handler := &RssHandler{}
 	now := time.Now()
@@ -121,11 +254,22 @@ func TestFormatMessage(t *testing.T) {
 		状态：待定
 		🔗 [阅读原文](https://example.com)`,
 		},
+		{
+			// 多个结果使用两个空格连接，与早期版本 prefix 的输出一致
+			name:     "Extract all with prefix",
+			template: `{description|extract-all:(\d+)|prefix:#}`,
+			expected: "#123  #2  #1234  #2024  #12  #10",
+		},
+		{
+			name:     "Extract all without join",
+			template: `{description|extract-all:(\d+)[号单]}`,
+			expected: "123  2",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := handler.formatMessage(item, tt.template)
+			result := handler.formatMessage(newItemContext("", nil, item), tt.template)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
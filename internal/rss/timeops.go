@@ -0,0 +1,158 @@
+package rss
+
+//时间类的模板操作符
+//字段为时间值（pubDate、updated）时直接处理，否则尝试从文本中解析时间
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 时间字段的默认格式
+const defaultTimeLayout = "2006-01-02 15:04:05"
+
+// 从文本解析时间时尝试的格式
+var timeParseLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	defaultTimeLayout,
+	"2006-01-02 15:04",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+}
+
+// parseTime 从文本解析时间，没有时区信息时使用本地时区
+func parseTime(content string) (time.Time, bool) {
+	content = strings.TrimSpace(content)
+	for _, layout := range timeParseLayouts {
+		if t, err := time.ParseInLocation(layout, content, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// DateOperation 按 Go 时间格式输出，例如 date:2006年01月02日 15:04
+type DateOperation struct{}
+
+func (op *DateOperation) Process(content string, layout string) string {
+	t, ok := parseTime(content)
+	if !ok {
+		return content
+	}
+	_, result := op.ProcessTime(t, layout)
+	return result
+}
+
+func (op *DateOperation) ProcessTime(t time.Time, layout string) (*time.Time, string) {
+	if layout == "" {
+		layout = defaultTimeLayout
+	}
+	return nil, t.Format(layout)
+}
+
+// TimezoneOperation 转换到指定的 IANA 时区，例如 tz:Asia/Shanghai
+type TimezoneOperation struct{}
+
+func (op *TimezoneOperation) Process(content string, zone string) string {
+	t, ok := parseTime(content)
+	if !ok {
+		return content
+	}
+	converted, _ := op.ProcessTime(t, zone)
+	return converted.Format(defaultTimeLayout)
+}
+
+func (op *TimezoneOperation) ProcessTime(t time.Time, zone string) (*time.Time, string) {
+	loc, err := time.LoadLocation(strings.TrimSpace(zone))
+	if err != nil {
+		log.Printf("Invalid timezone: %v", err)
+		return &t, ""
+	}
+	converted := t.In(loc)
+	return &converted, ""
+}
+
+func (op *TimezoneOperation) Compile(zone string) (Operation, error) {
+	loc, err := time.LoadLocation(strings.TrimSpace(zone))
+	if err != nil {
+		return nil, err
+	}
+	return &locationOperation{loc: loc}, nil
+}
+
+// locationOperation 已加载时区的 tz 操作
+type locationOperation struct {
+	loc *time.Location
+}
+
+func (op *locationOperation) Process(content string, _ string) string {
+	t, ok := parseTime(content)
+	if !ok {
+		return content
+	}
+	return t.In(op.loc).Format(defaultTimeLayout)
+}
+
+func (op *locationOperation) ProcessTime(t time.Time, _ string) (*time.Time, string) {
+	converted := t.In(op.loc)
+	return &converted, ""
+}
+
+// AgoOperation 输出相对时间，例如 “3 小时前”；参数为 en 时输出英文，例如 “3 hours ago”
+type AgoOperation struct{}
+
+func (op *AgoOperation) Process(content string, lang string) string {
+	t, ok := parseTime(content)
+	if !ok {
+		return content
+	}
+	_, result := op.ProcessTime(t, lang)
+	return result
+}
+
+func (op *AgoOperation) ProcessTime(t time.Time, lang string) (*time.Time, string) {
+	return nil, relativeTime(time.Since(t), strings.TrimSpace(lang) == "en")
+}
+
+// relativeTime 将时间间隔转换为相对时间描述
+func relativeTime(d time.Duration, english bool) string {
+	if d < 0 {
+		d = 0
+	}
+
+	units := []struct {
+		size time.Duration
+		zh   string
+		en   string
+	}{
+		{365 * 24 * time.Hour, "年", "year"},
+		{30 * 24 * time.Hour, "个月", "month"},
+		{24 * time.Hour, "天", "day"},
+		{time.Hour, "小时", "hour"},
+		{time.Minute, "分钟", "minute"},
+	}
+	for _, unit := range units {
+		n := int(d / unit.size)
+		if n < 1 {
+			continue
+		}
+		if !english {
+			return fmt.Sprintf("%d %s前", n, unit.zh)
+		}
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit.en)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit.en)
+	}
+
+	if english {
+		return "just now"
+	}
+	return "刚刚"
+}
@@ -0,0 +1,75 @@
+package rss
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatMessageTimeOperations(t *testing.T) {
+	handler := &RssHandler{}
+	published := time.Date(2024, 12, 10, 1, 30, 0, 0, time.UTC)
+	updated := time.Now().Add(-3 * time.Hour)
+
+	item := &gofeed.Item{
+		Title:           "测试标题 发布于2024-12-10 08:00",
+		PublishedParsed: &published,
+		UpdatedParsed:   &updated,
+	}
+	ctx := newItemContext("", nil, item)
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	assert.NoError(t, err)
+	ctxShanghai := newItemContext("", nil, item)
+	ctxShanghai.location = shanghai
+
+	tests := []struct {
+		name     string
+		ctx      *itemContext
+		template string
+		expected string
+	}{
+		{"Default layout", ctx, "{pubDate}", "2024-12-10 01:30:00"},
+		{"Date layout", ctx, "{pubDate|date:2006年01月02日 15:04}", "2024年12月10日 01:30"},
+		{"Timezone then layout", ctx, "{pubDate|tz:Asia/Shanghai|date:01-02 15:04}", "12-10 09:30"},
+		{"Timezone with default layout", ctx, "{pubDate|tz:Asia/Tokyo}", "2024-12-10 10:30:00"},
+		{"Configured timezone", ctxShanghai, "{pubDate}", "2024-12-10 09:30:00"},
+		{"Configured timezone with layout", ctxShanghai, "{pubDate|date:15:04 MST}", "09:30 CST"},
+		{"Relative time", ctx, "{updated|ago}", "3 小时前"},
+		{"Relative time in English", ctx, "{updated|ago:en}", "3 hours ago"},
+		{"Text operation after time", ctx, "{pubDate|date:2006-01-02|replace:-:/}", "2024/12/10"},
+		{"Date parsed from text", ctx, "{title|extract:发布于(.*)|date:01月02日}", "12月10日"},
+		{"Missing time", ctx, "{ext:dc:date|date:2006|default:未知}", "未知"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, handler.formatMessage(tt.ctx, tt.template))
+		})
+	}
+
+	t.Run("Go template", func(t *testing.T) {
+		result := handler.renderMessage(config.TemplateEngineGo, ctxShanghai, `{{.PubDate}} {{.Published | date "15:04"}} {{.Updated | ago "en"}}`)
+		assert.Equal(t, "2024-12-10 09:30:00 09:30 3 hours ago", result)
+	})
+}
+
+func TestRelativeTime(t *testing.T) {
+	tests := []struct {
+		d      time.Duration
+		zh, en string
+	}{
+		{30 * time.Second, "刚刚", "just now"},
+		{time.Minute, "1 分钟前", "1 minute ago"},
+		{26 * time.Hour, "1 天前", "1 day ago"},
+		{90 * 24 * time.Hour, "3 个月前", "3 months ago"},
+		{800 * 24 * time.Hour, "2 年前", "2 years ago"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.zh, relativeTime(tt.d, false))
+		assert.Equal(t, tt.en, relativeTime(tt.d, true))
+	}
+}
@@ -0,0 +1,68 @@
+package rss
+
+//已推送文章内容更新后的处理
+//根据 feed 的 on_update 配置编辑原消息或回复更新通知
+
+import (
+	"log"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+)
+
+// updateNotePrefix 更新通知的前缀
+func updateNotePrefix(parseMode string) string {
+	return "🔄 " + boldText(parseMode, "内容已更新") + "\n\n"
+}
+
+// processUpdates 检查已推送文章的内容哈希，变化时按配置处理
+func (h *RssHandler) processUpdates(feedConfig config.FeedConfig, feed *gofeed.Feed) {
+	if feedConfig.OnUpdate != config.OnUpdateEdit && feedConfig.OnUpdate != config.OnUpdateReply {
+		return
+	}
+
+	for _, item := range feed.Items {
+		if item.Title == "" && item.Link == "" {
+			continue
+		}
+		itemID := generateItemID(item)
+		hash := contentHash(item)
+		ctx := h.contextFor(feedConfig, feed, item)
+
+		for _, channel := range feedConfig.Channels {
+			record, exists := h.storage.GetMessage(feedConfig.URL, channel, itemID)
+			if !exists || record.Hash == hash {
+				continue
+			}
+
+			message := h.renderMessage(feedConfig.TemplateEngine, ctx, feedConfig.Template)
+			if message == "" {
+				continue
+			}
+			sendOpts := h.buildSendOptions(ctx, feedConfig, channel)
+
+			var err error
+			switch feedConfig.OnUpdate {
+			case config.OnUpdateEdit:
+				err = h.bot.Edit(channel, record.MessageID, message, sendOpts)
+			case config.OnUpdateReply:
+				_, err = h.bot.Reply(channel, record.MessageID, updateNotePrefix(feedConfig.ParseMode)+message, sendOpts)
+			}
+			if err != nil {
+				// 不更新哈希，下次检查时重试
+				log.Printf("Error applying update (%s) to channel %s: %s: %v", feedConfig.OnUpdate, channel, item.Title, err)
+				continue
+			}
+			log.Printf("Applied update (%s) to channel %s: %s", feedConfig.OnUpdate, channel, item.Title)
+
+			record.Hash = hash
+			if feedConfig.OnUpdate == config.OnUpdateEdit {
+				record.Text = message
+				record.Buttons = recordButtons(sendOpts)
+			}
+			if err := h.storage.SaveMessage(feedConfig.URL, channel, itemID, record); err != nil {
+				log.Printf("SaveMessage ERROR!!  channel %s: %v", channel, err)
+			}
+		}
+	}
+}
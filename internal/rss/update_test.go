@@ -0,0 +1,142 @@
+package rss
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/Hootrix/rss2telegram/internal/storage"
+	"github.com/Hootrix/rss2telegram/internal/telegram"
+	"github.com/mmcdole/gofeed"
+	"github.com/stretchr/testify/assert"
+)
+
+// botCall 记录 fakeBot 收到的调用
+type botCall struct {
+	action    string
+	channel   string
+	messageID int
+	text      string
+	opts      *telegram.SendOptions
+}
+
+// fakeBot 记录所有调用的 TelegramBot，err 不为 nil 时所有调用返回该错误
+type fakeBot struct {
+	calls  []botCall
+	nextID int
+	err    error
+}
+
+func (b *fakeBot) record(call botCall) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	b.calls = append(b.calls, call)
+	b.nextID++
+	return b.nextID, nil
+}
+
+func (b *fakeBot) Send(channel string, message string, opts *telegram.SendOptions) (int, error) {
+	return b.record(botCall{action: "send", channel: channel, text: message, opts: opts})
+}
+
+func (b *fakeBot) Edit(channel string, messageID int, message string, opts *telegram.SendOptions) error {
+	_, err := b.record(botCall{action: "edit", channel: channel, messageID: messageID, text: message, opts: opts})
+	return err
+}
+
+func (b *fakeBot) Reply(channel string, messageID int, message string, opts *telegram.SendOptions) (int, error) {
+	return b.record(botCall{action: "reply", channel: channel, messageID: messageID, text: message, opts: opts})
+}
+
+func (b *fakeBot) Delete(channel string, messageID int) error {
+	_, err := b.record(botCall{action: "delete", channel: channel, messageID: messageID})
+	return err
+}
+
+func TestProcessUpdates(t *testing.T) {
+	const feedURL = "https://example.com/rss.xml"
+	original := &gofeed.Item{Title: "A", Link: "https://example.com/a"}
+	changed := &gofeed.Item{Title: "A（更新）", Link: "https://example.com/a"}
+	itemID := generateItemID(original)
+
+	tests := []struct {
+		name      string
+		onUpdate  string
+		item      *gofeed.Item
+		botErr    error
+		wantCalls []botCall
+		wantHash  string
+		wantText  string
+	}{
+		{
+			name:     "内容未变化",
+			onUpdate: config.OnUpdateEdit,
+			item:     original,
+			wantHash: contentHash(original),
+			wantText: "A",
+		},
+		{
+			name:      "编辑原消息",
+			onUpdate:  config.OnUpdateEdit,
+			item:      changed,
+			wantCalls: []botCall{{action: "edit", channel: "@a", messageID: 10, text: "A（更新）"}},
+			wantHash:  contentHash(changed),
+			wantText:  "A（更新）",
+		},
+		{
+			name:      "回复原消息",
+			onUpdate:  config.OnUpdateReply,
+			item:      changed,
+			wantCalls: []botCall{{action: "reply", channel: "@a", messageID: 10, text: updateNotePrefix("") + "A（更新）"}},
+			wantHash:  contentHash(changed),
+			wantText:  "A",
+		},
+		{
+			name:     "忽略更新",
+			onUpdate: config.OnUpdateIgnore,
+			item:     changed,
+			wantHash: contentHash(original),
+			wantText: "A",
+		},
+		{
+			name:     "发送失败时不更新哈希",
+			onUpdate: config.OnUpdateEdit,
+			item:     changed,
+			botErr:   errors.New("telegram error"),
+			wantHash: contentHash(original),
+			wantText: "A",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := storage.NewStorage(t.TempDir())
+			assert.NoError(t, err)
+			assert.NoError(t, store.SaveMessage(feedURL, "@a", itemID, storage.MessageRecord{
+				MessageID: 10, Hash: contentHash(original), Text: "A", SentAt: time.Now(),
+			}))
+
+			bot := &fakeBot{err: tt.botErr}
+			handler := NewRssHandler(&config.Config{}, bot, store)
+			feedConfig := config.FeedConfig{
+				Name:     "示例",
+				URL:      feedURL,
+				Channels: []string{"@a", "@b"},
+				Template: "{title}",
+				OnUpdate: tt.onUpdate,
+			}
+			handler.processUpdates(feedConfig, &gofeed.Feed{Items: []*gofeed.Item{tt.item}})
+
+			// 发送选项由 TestBuildSendOptions 检查
+			for i := range bot.calls {
+				bot.calls[i].opts = nil
+			}
+			// @b 没有发送记录，不处理
+			assert.Equal(t, tt.wantCalls, bot.calls)
+			record, _ := store.GetMessage(feedURL, "@a", itemID)
+			assert.Equal(t, tt.wantHash, record.Hash)
+			assert.Equal(t, tt.wantText, record.Text)
+		})
+	}
+}
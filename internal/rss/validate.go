@@ -0,0 +1,229 @@
+package rss
+
+//配置加载时的模板校验
+//模板中不支持的字段、操作符以及无法编译的正则表达式在加载配置时报告，避免运行时静默忽略
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template/parse"
+	"unicode/utf8"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/mmcdole/gofeed"
+)
+
+// templateError 模板错误，包含出错位置的行号和列号（从 1 开始，按字符计算）
+type templateError struct {
+	line, column int
+	msg          string
+}
+
+func newTemplateError(text string, offset int, format string, args ...interface{}) *templateError {
+	before := text[:offset]
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return &templateError{
+		line:   strings.Count(before, "\n") + 1,
+		column: utf8.RuneCountInString(before[lineStart:]) + 1,
+		msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *templateError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.line, e.column, e.msg)
+}
+
+// ValidateTemplate 检查模板是否有效，作为 config.TemplateValidator 在加载配置时使用
+// Go 模板引擎检查语法、函数名称和字段，花括号语法检查条件区块、字段、操作符及其参数
+// 另一种模板语法的字段视为模板引擎设置错误，例如 Go 模板中的 {title} 和花括号语法中的 {{.Title}}
+func ValidateTemplate(engine, text string) error {
+	if engine == config.TemplateEngineGo {
+		tpl, err := compileGoTemplate(text)
+		if err != nil {
+			return err
+		}
+		for _, t := range tpl.tpl.Templates() {
+			if t.Tree == nil {
+				continue
+			}
+			c := goFieldChecker{text: text}
+			if err := c.walk(t.Tree.Root, templateDataType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if m := goActionRegex.FindStringIndex(text); m != nil {
+		return newTemplateError(text, m[0], "go template syntax %s in brace template, set template_engine: go", text[m[0]:m[1]])
+	}
+	_, err := compileBraceTemplate(text, true)
+	return err
+}
+
+// goActionRegex Go 模板的字段、变量、注释和控制结构
+var goActionRegex = regexp.MustCompile(`\{\{-?\s*(?:\.|\$|/\*|(?:if|else|end|range|with|define|template|block)\b).*?\}\}`)
+
+// Go 模板的数据类型
+var templateDataType = reflect.TypeOf(&templateData{})
+
+// goFieldChecker 遍历 Go 模板的语法树，检查字段是否存在以及 field 函数的字段名称
+// 字段按 . 的类型静态检查，with 和 range 中 . 的类型无法确定时不检查
+type goFieldChecker struct {
+	text string
+}
+
+// walk 检查节点，dot 为 . 的类型，为 nil 时表示类型未知
+func (c *goFieldChecker) walk(node parse.Node, dot reflect.Type) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := c.walk(child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.TextNode:
+		return c.textNode(n)
+	case *parse.ActionNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.TemplateNode:
+		_, err := c.pipe(n.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		return c.branch(&n.BranchNode, dot, false)
+	case *parse.WithNode:
+		return c.branch(&n.BranchNode, dot, true)
+	case *parse.RangeNode:
+		return c.branch(&n.BranchNode, dot, true)
+	}
+	return nil
+}
+
+// textNode 检查 Go 模板的文本中是否有花括号语法的字段
+func (c *goFieldChecker) textNode(n *parse.TextNode) error {
+	for _, m := range compactFieldRegex.FindAllSubmatchIndex(n.Text, -1) {
+		field := strings.TrimSpace(string(n.Text[m[2]:m[3]]))
+		if knownField(strings.SplitN(field, "|", 2)[0]) {
+			return newTemplateError(c.text, int(n.Pos)+m[0], "brace template field %s in go template, set template_engine: brace", n.Text[m[0]:m[1]])
+		}
+	}
+	return nil
+}
+
+// branch 检查 if、with、range，changeDot 为 true 时区块中的 . 为管道的值（range 中为元素）
+func (c *goFieldChecker) branch(n *parse.BranchNode, dot reflect.Type, changeDot bool) error {
+	t, err := c.pipe(n.Pipe, dot)
+	if err != nil {
+		return err
+	}
+	inner := dot
+	if changeDot {
+		inner = t
+		if n.NodeType == parse.NodeRange && t != nil {
+			inner = elemType(t)
+		}
+	}
+	if err := c.walk(n.List, inner); err != nil {
+		return err
+	}
+	return c.walk(n.ElseList, dot)
+}
+
+// pipe 检查管道中的所有命令，返回管道值的类型，无法确定时返回 nil
+func (c *goFieldChecker) pipe(pipe *parse.PipeNode, dot reflect.Type) (reflect.Type, error) {
+	if pipe == nil {
+		return nil, nil
+	}
+	var result reflect.Type
+	for _, cmd := range pipe.Cmds {
+		result = nil
+		for i, arg := range cmd.Args {
+			t, err := c.arg(arg, dot)
+			if err != nil {
+				return nil, err
+			}
+			if len(cmd.Args) == 1 {
+				result = t
+			}
+			// {{field "name"}} 检查字段名称
+			if ident, ok := arg.(*parse.IdentifierNode); ok && ident.Ident == "field" && i == 0 && len(cmd.Args) > 1 {
+				if name, ok := cmd.Args[1].(*parse.StringNode); ok && !knownField(name.Text) {
+					return nil, newTemplateError(c.text, int(name.Position()), "unknown field %q in field function", name.Text)
+				}
+			}
+		}
+	}
+	if len(pipe.Decl) > 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// arg 检查命令参数，返回参数值的类型，无法确定时返回 nil
+func (c *goFieldChecker) arg(node parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return c.fields(n, dot, n.Ident)
+	case *parse.VariableNode:
+		// $ 为模板数据，其他变量的类型未知
+		if n.Ident[0] == "$" {
+			return c.fields(n, templateDataType, n.Ident[1:])
+		}
+	case *parse.PipeNode:
+		return c.pipe(n, dot)
+	}
+	return nil, nil
+}
+
+// fields 按字段链检查 t 的字段，返回最后一个字段的类型
+func (c *goFieldChecker) fields(node parse.Node, t reflect.Type, names []string) (reflect.Type, error) {
+	for _, name := range names {
+		if t == nil {
+			return nil, nil
+		}
+		for t.Kind() == reflect.Ptr {
+			if _, ok := t.MethodByName(name); ok {
+				return nil, nil
+			}
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			// map 和 interface 等类型的字段在执行时确定
+			return nil, nil
+		}
+		field, ok := t.FieldByName(name)
+		if !ok || !field.IsExported() {
+			if _, ok := reflect.PtrTo(t).MethodByName(name); ok {
+				return nil, nil
+			}
+			return nil, newTemplateError(c.text, int(node.Position()), "unknown field %q in {{%s}}", name, node)
+		}
+		t = field.Type
+	}
+	return t, nil
+}
+
+// elemType 返回 range 中元素的类型，无法确定时返回 nil
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return t.Elem()
+	}
+	return nil
+}
+
+// knownField 判断是否为支持的字段
+func knownField(name string) bool {
+	_, ok := resolveField(newItemContext("", nil, &gofeed.Item{}), name, nil)
+	return ok
+}
@@ -0,0 +1,92 @@
+package rss
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Hootrix/rss2telegram/internal/config"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		engine   string
+		template string
+		err      string
+	}{
+		{"Valid", "", "📰 *{title}*\n\n{description|extract:价格：(\\d+)元|default:未知}\n{ title|replace:\\d{4}:**** }", ""},
+		{"Valid conditional", "", "{?author}作者：{author}{/author}{!categories|join: }无分类{/categories}", ""},
+		{"Extension fields", "", "{ext:media:thumbnail@url} {itunes:duration}", ""},
+		{"Unknown field", "", "{title}\n  {titel}", `line 2, column 3: unknown field "titel"`},
+		{"Unknown operator", "", "{title|extarct:(\\d+)}", `line 1, column 1: unknown operator "extarct" in {title|extarct:(\d+)}`},
+		{"Invalid regex", "", "价格 {description|extract:([0-9}", "line 1, column 4: extract: error parsing regexp"},
+		{"Invalid replace", "", "{title|replace:abc}", "replace requires pattern:replacement"},
+		{"Invalid timezone", "", "{pubDate|tz:Mars/Olympus}", "unknown time zone Mars/Olympus"},
+		{"Invalid count", "", "{title|truncate:abc}", `invalid count "abc"`},
+		{"Unknown field in condition", "", "{?titel}{title}{/titel}", `line 1, column 1: unknown field "titel"`},
+		{"Unclosed condition", "", "{title}\n{?author}{author}", "line 2, column 1: unclosed conditional block {?author}"},
+		{"Go template", config.TemplateEngineGo, `{{.Title | extract "(\\d+)"}}`, ""},
+		{"Go template unknown function", config.TemplateEngineGo, `{{.Title | extarct "(\\d+)"}}`, `function "extarct" not defined`},
+		{"Go template fields", config.TemplateEngineGo, "{{.Feed.Name}} {{.Item.Title}} {{$.Link}} {{range .Enclosures}}{{.URL}}{{end}} {{with .Published}}{{.Year}}{{end}} {{field \"ext:media:thumbnail@url\"}}", ""},
+		{"Go template unknown field", config.TemplateEngineGo, "{{.Title}}\n{{if .Link}}{{.Titel}}{{end}}", `line 2, column 15: unknown field "Titel" in {{.Titel}}`},
+		{"Go template unknown nested field", config.TemplateEngineGo, "{{.Feed.Titel}}", `unknown field "Titel" in {{.Feed.Titel}}`},
+		{"Go template unknown field in range", config.TemplateEngineGo, "{{range .Enclosures}}{{.Link}}{{end}}", `unknown field "Link"`},
+		{"Go template unknown field in else", config.TemplateEngineGo, "{{with .Author}}{{.}}{{else}}{{.Auther}}{{end}}", `unknown field "Auther"`},
+		{"Go template unknown field function", config.TemplateEngineGo, `{{field "titel"}}`, `unknown field "titel" in field function`},
+		{"Brace field in Go template", config.TemplateEngineGo, "{{.Title}}\n[原文]({link})", `line 2, column 6: brace template field {link} in go template, set template_engine: brace`},
+		{"Brace field with operator in Go template", config.TemplateEngineGo, "{{if .Link}}{title|truncate:10}{{end}}", `line 1, column 13: brace template field {title|truncate:10} in go template`},
+		{"Literal braces in Go template", config.TemplateEngineGo, "{{.Title}} {count} {}", ""},
+		{"Go syntax in brace template", "", "{title}\n{{ .Link }}", `line 2, column 1: go template syntax {{ .Link }} in brace template, set template_engine: go`},
+		{"Go control in brace template", config.TemplateEngineBrace, "{{if .Author}}{author}{{end}}", `go template syntax {{if .Author}} in brace template`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplate(tt.engine, tt.template)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.err)
+			}
+		})
+	}
+}
+
+func TestConfigValidateTemplates(t *testing.T) {
+	newConfig := func(feed config.FeedConfig) *config.Config {
+		feed.Name = "示例"
+		feed.URL = "https://example.com/rss.xml"
+		feed.Channels = []string{"@channel"}
+		return &config.Config{
+			Telegram: config.TelegramConfig{BotToken: "token", CheckInterval: 300},
+			Feeds:    []config.FeedConfig{feed},
+		}
+	}
+
+	err := newConfig(config.FeedConfig{Template: "{title|extarct:(\\d+)}"}).Validate(ValidateTemplate)
+	assert.EqualError(t, err, `feeds[0]: feed 示例: template: line 1, column 1: unknown operator "extarct" in {title|extarct:(\d+)}`)
+
+	err = newConfig(config.FeedConfig{Buttons: []config.ButtonConfig{{Text: "评论", URL: "{comment}"}}}).Validate(ValidateTemplate)
+	assert.EqualError(t, err, `feeds[0]: feed 示例: button 1 url: line 1, column 1: unknown field "comment"`)
+
+	// 模板语法与模板引擎不一致
+	err = newConfig(config.FeedConfig{Template: "{title}", TemplateEngine: config.TemplateEngineGo}).Validate(ValidateTemplate)
+	assert.EqualError(t, err, `feeds[0]: feed 示例: template: line 1, column 1: brace template field {title} in go template, set template_engine: brace`)
+
+	assert.NoError(t, newConfig(config.FeedConfig{Template: "{title}\n\n{link}"}).Validate(ValidateTemplate))
+}
+
+func TestExampleConfigTemplates(t *testing.T) {
+	data, err := os.ReadFile("../../config/config.yaml.example")
+	assert.NoError(t, err)
+
+	var cfg config.Config
+	assert.NoError(t, yaml.Unmarshal(data, &cfg))
+	for _, feed := range cfg.Feeds {
+		assert.NoError(t, ValidateTemplate(feed.TemplateEngine, feed.Template), feed.Name)
+	}
+}
@@ -0,0 +1,344 @@
+package storage
+
+//已发送消息的记录存储
+//每个rss地址和channel对应一个json文件，与bloom文件同名
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const metaFileSuffix = ".meta.json"
+
+// MessageRecord 已发送消息的记录
+type MessageRecord struct {
+	MessageID int       `json:"message_id"`
+	Hash      string    `json:"hash"` // 发送时的文章内容哈希
+	Text      string    `json:"text"` // 发送的消息内容
+	SentAt    time.Time `json:"sent_at"`
+	Missing   int       `json:"missing,omitempty"` // 文章连续从feed中消失的次数
+
+	// 消息的内联按钮，文章从 feed 中移除后编辑消息时保留
+	Buttons []MessageButton `json:"buttons,omitempty"`
+}
+
+// MessageButton 已发送消息的内联链接按钮
+type MessageButton struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
+// QueuedItem 等待推送的文章
+type QueuedItem struct {
+	ItemID   string       `json:"item_id"`
+	Item     *gofeed.Item `json:"item"`
+	Feed     *gofeed.Feed `json:"feed,omitempty"` // feed 的元数据（不含文章列表），用于渲染模板
+	QueuedAt time.Time    `json:"queued_at"`
+}
+
+// channelMeta 单个 feed+channel 的附加状态
+type channelMeta struct {
+	Messages map[string]*MessageRecord `json:"messages"` // itemID -> record
+
+	Digest      []QueuedItem `json:"digest,omitempty"`       // 等待摘要推送的文章
+	DigestSince time.Time    `json:"digest_since,omitempty"` // 当前摘要周期的开始时间
+
+	Held []QueuedItem `json:"held,omitempty"` // 免打扰时段暂存的文章
+}
+
+func newChannelMeta() *channelMeta {
+	return &channelMeta{
+		Messages: make(map[string]*MessageRecord),
+	}
+}
+
+// GetMetaFilePath 获取附加状态的文件路径
+func (s *Storage) GetMetaFilePath(feedURL string, channel string) string {
+	return filepath.Join(s.dataDir, s.GenerateBloomFileName(feedURL, channel)+metaFileSuffix)
+}
+
+// GetMessage 获取item在channel中已发送消息的记录
+func (s *Storage) GetMessage(feedURL, channel, itemID string) (MessageRecord, bool) {
+	s.RLock()
+	defer s.RUnlock()
+
+	meta, exists := s.metas[s.GenerateBloomFileName(feedURL, channel)]
+	if !exists {
+		return MessageRecord{}, false
+	}
+	record, exists := meta.Messages[itemID]
+	if !exists {
+		return MessageRecord{}, false
+	}
+	return *record, true
+}
+
+// SaveMessage 保存item在channel中已发送消息的记录
+func (s *Storage) SaveMessage(feedURL, channel, itemID string, record MessageRecord) error {
+	s.Lock()
+	defer s.Unlock()
+
+	meta := s.getOrCreateMeta(feedURL, channel)
+	meta.Messages[itemID] = &record
+
+	if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+		return fmt.Errorf("error saving channel meta: %w", err)
+	}
+	return nil
+}
+
+// ListMessages 获取channel中所有已发送消息的记录
+func (s *Storage) ListMessages(feedURL, channel string) map[string]MessageRecord {
+	s.RLock()
+	defer s.RUnlock()
+
+	records := make(map[string]MessageRecord)
+	if meta, exists := s.metas[s.GenerateBloomFileName(feedURL, channel)]; exists {
+		for itemID, record := range meta.Messages {
+			records[itemID] = *record
+		}
+	}
+	return records
+}
+
+// UpdateMessages 批量更新channel中的消息记录，值为nil时删除该记录
+func (s *Storage) UpdateMessages(feedURL, channel string, updates map[string]*MessageRecord) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	meta := s.getOrCreateMeta(feedURL, channel)
+	for itemID, record := range updates {
+		if record == nil {
+			delete(meta.Messages, itemID)
+			continue
+		}
+		meta.Messages[itemID] = record
+	}
+
+	if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+		return fmt.Errorf("error saving channel meta: %w", err)
+	}
+	return nil
+}
+
+// EnqueueDigest 将文章加入摘要队列，已在队列中的文章会被忽略
+func (s *Storage) EnqueueDigest(feedURL, channel, itemID string, feed *gofeed.Feed, item *gofeed.Item) error {
+	s.Lock()
+	defer s.Unlock()
+
+	meta := s.getOrCreateMeta(feedURL, channel)
+	for _, queued := range meta.Digest {
+		if queued.ItemID == itemID {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	if len(meta.Digest) == 0 && meta.DigestSince.IsZero() {
+		meta.DigestSince = now
+	}
+	meta.Digest = append(meta.Digest, QueuedItem{ItemID: itemID, Item: item, Feed: feed, QueuedAt: now})
+
+	if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+		return fmt.Errorf("error saving channel meta: %w", err)
+	}
+	return nil
+}
+
+// PendingDigest 获取摘要队列中的文章和当前摘要周期的开始时间
+func (s *Storage) PendingDigest(feedURL, channel string) ([]QueuedItem, time.Time) {
+	s.RLock()
+	defer s.RUnlock()
+
+	meta, exists := s.metas[s.GenerateBloomFileName(feedURL, channel)]
+	if !exists {
+		return nil, time.Time{}
+	}
+	items := make([]QueuedItem, len(meta.Digest))
+	copy(items, meta.Digest)
+	return items, meta.DigestSince
+}
+
+// CompleteDigest 从摘要队列头部移除已推送的 count 篇文章
+// finished 为 true 时表示本轮摘要推送完成，开始新的摘要周期
+func (s *Storage) CompleteDigest(feedURL, channel string, count int, finished bool) error {
+	s.Lock()
+	defer s.Unlock()
+
+	meta := s.getOrCreateMeta(feedURL, channel)
+	if count > len(meta.Digest) {
+		count = len(meta.Digest)
+	}
+	meta.Digest = meta.Digest[count:]
+	if finished {
+		meta.DigestSince = time.Now()
+	}
+
+	if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+		return fmt.Errorf("error saving channel meta: %w", err)
+	}
+	return nil
+}
+
+// HoldItem 暂存免打扰时段的文章，已暂存的文章会被忽略
+func (s *Storage) HoldItem(feedURL, channel, itemID string, feed *gofeed.Feed, item *gofeed.Item) error {
+	s.Lock()
+	defer s.Unlock()
+
+	meta := s.getOrCreateMeta(feedURL, channel)
+	for _, held := range meta.Held {
+		if held.ItemID == itemID {
+			return nil
+		}
+	}
+	meta.Held = append(meta.Held, QueuedItem{ItemID: itemID, Item: item, Feed: feed, QueuedAt: time.Now()})
+
+	if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+		return fmt.Errorf("error saving channel meta: %w", err)
+	}
+	return nil
+}
+
+// HeldItems 获取暂存的文章，按暂存顺序排列
+func (s *Storage) HeldItems(feedURL, channel string) []QueuedItem {
+	s.RLock()
+	defer s.RUnlock()
+
+	meta, exists := s.metas[s.GenerateBloomFileName(feedURL, channel)]
+	if !exists {
+		return nil
+	}
+	items := make([]QueuedItem, len(meta.Held))
+	copy(items, meta.Held)
+	return items
+}
+
+// ReleaseHeld 从暂存队列中移除文章
+func (s *Storage) ReleaseHeld(feedURL, channel, itemID string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	meta := s.getOrCreateMeta(feedURL, channel)
+	for i, held := range meta.Held {
+		if held.ItemID == itemID {
+			meta.Held = append(meta.Held[:i], meta.Held[i+1:]...)
+			break
+		}
+	}
+
+	if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+		return fmt.Errorf("error saving channel meta: %w", err)
+	}
+	return nil
+}
+
+// DropHeld 删除 keep 返回 false 的频道中暂存的文章，返回删除的文章数量
+// 用于配置更新后清理已移除的 feed 或频道，这些文章不会再被推送
+func (s *Storage) DropHeld(keep func(feedURL, channel string) bool) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	dropped := 0
+	for key, meta := range s.metas {
+		if len(meta.Held) == 0 {
+			continue
+		}
+		channel, feedURL, err := parseStateFileName(key, "")
+		if err != nil || channel == "" || keep(feedURL, channel) {
+			continue
+		}
+		dropped += len(meta.Held)
+		meta.Held = nil
+		if err := s.saveChannelMeta(feedURL, channel, meta); err != nil {
+			return dropped, fmt.Errorf("error saving channel meta: %w", err)
+		}
+	}
+	return dropped, nil
+}
+
+// 获取channel的附加状态，不存在时创建。调用方需持有写锁
+func (s *Storage) getOrCreateMeta(feedURL, channel string) *channelMeta {
+	key := s.GenerateBloomFileName(feedURL, channel)
+	meta, exists := s.metas[key]
+	if !exists {
+		meta = newChannelMeta()
+		s.metas[key] = meta
+	}
+	return meta
+}
+
+// 读取channel附加状态的持久化存储
+func (s *Storage) loadChannelMeta(feedURL string, channel string) error {
+	data, err := os.ReadFile(s.GetMetaFilePath(feedURL, channel))
+	if err != nil {
+		return fmt.Errorf("error reading file: %w", err)
+	}
+
+	meta := newChannelMeta()
+	if err := json.Unmarshal(data, meta); err != nil {
+		return fmt.Errorf("error unmarshaling meta: %w", err)
+	}
+	if meta.Messages == nil {
+		meta.Messages = make(map[string]*MessageRecord)
+	}
+
+	// 清理过期的消息记录
+	for itemID, record := range meta.Messages {
+		if time.Since(record.SentAt) > stateExpirationDuration {
+			delete(meta.Messages, itemID)
+		}
+	}
+
+	s.metas[s.GenerateBloomFileName(feedURL, channel)] = meta
+	return nil
+}
+
+// 将channel附加状态保存到文件
+func (s *Storage) saveChannelMeta(feedURL string, channel string, meta *channelMeta) error {
+	if s.readOnly {
+		return nil
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("error marshaling meta: %w", err)
+	}
+	return writeFileAtomic(s.GetMetaFilePath(feedURL, channel), data)
+}
+
+// 通过临时文件+重命名的方式原子写入文件
+func writeFileAtomic(path string, data []byte) error {
+	tempFile := path + ".tmp"
+	file, err := os.OpenFile(tempFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("error writing data: %w", err)
+	}
+
+	// 确保所有数据都写入磁盘
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("error syncing file: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing file: %w", err)
+	}
+
+	// 原子重命名
+	if err := os.Rename(tempFile, path); err != nil {
+		return fmt.Errorf("error renaming temp file: %w", err)
+	}
+	return nil
+}
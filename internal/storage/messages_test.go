@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testFeedURL = "https://example.com/rss.xml"
+	testChannel = "@a"
+)
+
+func newTestStorage(t *testing.T, dataDir string) *Storage {
+	s, err := NewStorage(dataDir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return s
+}
+
+func TestSaveMessage(t *testing.T) {
+	dataDir := t.TempDir()
+	s := newTestStorage(t, dataDir)
+
+	_, exists := s.GetMessage(testFeedURL, testChannel, "a")
+	assert.False(t, exists)
+
+	record := MessageRecord{MessageID: 1, Hash: "h1", Text: "A", SentAt: time.Now().Round(0)}
+	assert.NoError(t, s.SaveMessage(testFeedURL, testChannel, "a", record))
+	got, exists := s.GetMessage(testFeedURL, testChannel, "a")
+	assert.True(t, exists)
+	assert.Equal(t, record, got)
+
+	// 其他频道的记录互不影响
+	_, exists = s.GetMessage(testFeedURL, "@b", "a")
+	assert.False(t, exists)
+
+	// 重新加载后记录仍然存在
+	reloaded := newTestStorage(t, dataDir)
+	got, exists = reloaded.GetMessage(testFeedURL, testChannel, "a")
+	assert.True(t, exists)
+	assert.Equal(t, record.MessageID, got.MessageID)
+	assert.Equal(t, record.Hash, got.Hash)
+	assert.True(t, record.SentAt.Equal(got.SentAt))
+}
+
+func TestListAndUpdateMessages(t *testing.T) {
+	dataDir := t.TempDir()
+	s := newTestStorage(t, dataDir)
+	now := time.Now()
+	assert.NoError(t, s.SaveMessage(testFeedURL, testChannel, "a", MessageRecord{MessageID: 1, SentAt: now}))
+	assert.NoError(t, s.SaveMessage(testFeedURL, testChannel, "b", MessageRecord{MessageID: 2, SentAt: now}))
+
+	records := s.ListMessages(testFeedURL, testChannel)
+	assert.Len(t, records, 2)
+	// 返回的是副本，修改不影响存储
+	record := records["a"]
+	record.Missing = 5
+	records["a"] = record
+	got, _ := s.GetMessage(testFeedURL, testChannel, "a")
+	assert.Equal(t, 0, got.Missing)
+
+	// nil 删除记录，其他值替换记录
+	assert.NoError(t, s.UpdateMessages(testFeedURL, testChannel, map[string]*MessageRecord{
+		"a": nil,
+		"b": {MessageID: 2, Missing: 1, SentAt: now},
+		"c": {MessageID: 3, SentAt: now},
+	}))
+	records = newTestStorage(t, dataDir).ListMessages(testFeedURL, testChannel)
+	assert.Len(t, records, 2)
+	assert.NotContains(t, records, "a")
+	assert.Equal(t, 1, records["b"].Missing)
+	assert.Equal(t, 3, records["c"].MessageID)
+
+	assert.Empty(t, s.ListMessages(testFeedURL, "@b"))
+}
+
+func TestLoadChannelMetaExpiration(t *testing.T) {
+	dataDir := t.TempDir()
+	s := newTestStorage(t, dataDir)
+	assert.NoError(t, s.UpdateMessages(testFeedURL, testChannel, map[string]*MessageRecord{
+		"old": {MessageID: 1, SentAt: time.Now().Add(-stateExpirationDuration - time.Hour)},
+		"new": {MessageID: 2, SentAt: time.Now()},
+	}))
+
+	// 加载时清理超过状态过期时间的记录
+	records := newTestStorage(t, dataDir).ListMessages(testFeedURL, testChannel)
+	assert.Len(t, records, 1)
+	assert.Contains(t, records, "new")
+}
+
+func TestReadOnlyMessages(t *testing.T) {
+	dataDir := t.TempDir()
+	s := newTestStorage(t, dataDir)
+	s.SetReadOnly(true)
+
+	assert.NoError(t, s.SaveMessage(testFeedURL, testChannel, "a", MessageRecord{MessageID: 1, SentAt: time.Now()}))
+	_, exists := s.GetMessage(testFeedURL, testChannel, "a")
+	assert.True(t, exists)
+
+	files, err := os.ReadDir(dataDir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	data, _ := json.Marshal(map[string]int{"a": 1})
+	assert.NoError(t, writeFileAtomic(path, data))
+	got, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	// 不留下临时文件
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+
+	// 目录不存在时返回错误
+	assert.Error(t, writeFileAtomic(filepath.Join(t.TempDir(), "missing", "state.json"), data))
+}
+
+func TestDropHeld(t *testing.T) {
+	dataDir := t.TempDir()
+	s := newTestStorage(t, dataDir)
+	assert.NoError(t, s.HoldItem(testFeedURL, "@a", "1", nil, nil))
+	assert.NoError(t, s.HoldItem(testFeedURL, "@b", "1", nil, nil))
+	assert.NoError(t, s.HoldItem(testFeedURL, "@b", "2", nil, nil))
+
+	dropped, err := s.DropHeld(func(feedURL, channel string) bool {
+		return feedURL == testFeedURL && channel == "@a"
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dropped)
+	assert.Len(t, s.HeldItems(testFeedURL, "@a"), 1)
+	assert.Empty(t, s.HeldItems(testFeedURL, "@b"))
+
+	// 删除结果已保存
+	assert.Empty(t, newTestStorage(t, dataDir).HeldItems(testFeedURL, "@b"))
+}
@@ -37,7 +37,10 @@ type ChannelState struct {
 type Storage struct {
 	sync.RWMutex
 	states  map[string]map[string]*ChannelState // feedURL -> channel -> state
+	metas   map[string]*channelMeta             // bloom文件名 -> 附加状态
 	dataDir string
+	// 只读模式下状态只保存在内存中，不写入文件
+	readOnly bool
 }
 
 // rss发布状态的存储桶
@@ -48,6 +51,7 @@ func NewStorage(dataDir string) (*Storage, error) {
 
 	s := &Storage{
 		states:  make(map[string]map[string]*ChannelState),
+		metas:   make(map[string]*channelMeta),
 		dataDir: dataDir,
 	}
 
@@ -59,22 +63,13 @@ func NewStorage(dataDir string) (*Storage, error) {
 
 	// 遍历所有bloom文件
 	for _, file := range files {
-		// 从文件名中提取信息
-		filename := filepath.Base(file)
-		// 移除 .bloom 后缀
-		encoded := strings.TrimSuffix(filename, bloomFileSuffix)
-		decoded, err := base64.URLEncoding.DecodeString(encoded)
+		channel, feedURL, err := parseStateFileName(file, bloomFileSuffix)
 		if err != nil {
-			return nil, fmt.Errorf("decoding filename %s: %w", filename, err)
+			return nil, err
 		}
-
-		// 解析出 channel 和 URL
-		parts := strings.SplitN(string(decoded), "|", 2)
-		if len(parts) != 2 {
-			log.Printf("Warning: invalid format file found: %s, will be recreated", file)
+		if channel == "" {
 			continue
 		}
-		channel, feedURL := parts[0], parts[1]
 
 		// 加载channel状态
 		if err := s.loadChannelState(feedURL, channel); err != nil {
@@ -82,9 +77,48 @@ func NewStorage(dataDir string) (*Storage, error) {
 		}
 	}
 
+	// 加载所有 channel 的附加状态
+	metaFiles, err := filepath.Glob(filepath.Join(dataDir, "*"+metaFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range metaFiles {
+		channel, feedURL, err := parseStateFileName(file, metaFileSuffix)
+		if err != nil {
+			return nil, err
+		}
+		if channel == "" {
+			continue
+		}
+
+		if err := s.loadChannelMeta(feedURL, channel); err != nil {
+			return nil, fmt.Errorf("loading meta for %s channel %s: %w", feedURL, channel, err)
+		}
+	}
+
 	return s, nil
 }
 
+// 从状态文件名中解析出 channel 和 feedURL，格式无效时返回空值
+func parseStateFileName(file string, suffix string) (string, string, error) {
+	// 从文件名中提取信息
+	filename := filepath.Base(file)
+	// 移除后缀
+	encoded := strings.TrimSuffix(filename, suffix)
+	decoded, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", "", fmt.Errorf("decoding filename %s: %w", filename, err)
+	}
+
+	// 解析出 channel 和 URL
+	parts := strings.SplitN(string(decoded), "|", 2)
+	if len(parts) != 2 {
+		log.Printf("Warning: invalid format file found: %s, will be recreated", file)
+		return "", "", nil
+	}
+	return parts[0], parts[1], nil
+}
+
 // 生成布隆过滤器的文件名
 func (s *Storage) GenerateBloomFileName(feedURL string, channel string) string {
 	// 使用channel和feedURL生成文件名
@@ -117,6 +151,13 @@ func (s *Storage) IsItemSeen(feedURL, feedName, channel, itemID string) bool {
 	return state.filter.Test([]byte(itemID))
 }
 
+// SetReadOnly 设置只读模式，用于 dry-run 等不应修改推送状态的场景
+func (s *Storage) SetReadOnly(readOnly bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.readOnly = readOnly
+}
+
 // 标记item为已处理
 func (s *Storage) MarkItemSeen(feedURL, feedName, channel, itemID string) error {
 	s.Lock()
@@ -233,6 +274,9 @@ func (s *Storage) loadChannelState(feedURL string, channel string) error {
 
 // 将channel状态保存到文件
 func (s *Storage) saveChannelState(feedURL string, channel string, state *ChannelState) error {
+	if s.readOnly {
+		return nil
+	}
 	filepath := s.GetBloomFilePath(feedURL, channel)
 
 	// 创建临时文件
@@ -288,3 +332,12 @@ func (s *Storage) GetLastUpdated(feedURL string, channel string) time.Time {
 	}
 	return time.Time{}
 }
+
+// HasChannelState 判断是否已有 channel 的推送状态（从文件加载或本次运行中标记过文章）
+func (s *Storage) HasChannelState(feedURL string, channel string) bool {
+	s.RLock()
+	defer s.RUnlock()
+
+	_, exists := s.states[feedURL][channel]
+	return exists
+}
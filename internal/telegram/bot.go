@@ -1,6 +1,8 @@
 package telegram
 
 import (
+	"errors"
+	"strconv"
 	"time"
 
 	tele "gopkg.in/telebot.v3"
@@ -10,6 +12,29 @@ type Bot struct {
 	bot *tele.Bot
 }
 
+// 消息解析模式，为空时使用 Markdown
+const (
+	ParseModeMarkdown   = "Markdown"
+	ParseModeMarkdownV2 = "MarkdownV2"
+	ParseModeHTML       = "HTML"
+	ParseModeNone       = "none" // 纯文本
+)
+
+// SendOptions 发送消息时的可选项
+type SendOptions struct {
+	ParseMode             string   `json:"parse_mode,omitempty"`
+	DisableNotification   bool     `json:"disable_notification,omitempty"`     // 静默发送
+	DisableWebPagePreview bool     `json:"disable_web_page_preview,omitempty"` // 不显示链接预览
+	ProtectContent        bool     `json:"protect_content,omitempty"`          // 禁止转发和保存
+	Buttons               []Button `json:"buttons,omitempty"`
+}
+
+// Button 内联链接按钮
+type Button struct {
+	Text string `json:"text"`
+	URL  string `json:"url"`
+}
+
 func NewBot(token string) (*Bot, error) {
 	pref := tele.Settings{
 		Token:  token,
@@ -24,15 +49,93 @@ func NewBot(token string) (*Bot, error) {
 	return &Bot{bot: b}, nil
 }
 
-func (b *Bot) Send(channel string, message string) error {
+// Send 发送消息，返回消息 ID
+func (b *Bot) Send(channel string, message string, opts *SendOptions) (int, error) {
 
+	chat, err := b.bot.ChatByUsername(channel)
+	if err != nil {
+		return 0, err
+	}
+
+	msg, err := b.bot.Send(chat, message, buildSendOptions(opts))
+	if err != nil {
+		return 0, err
+	}
+	return msg.ID, nil
+}
+
+// Edit 编辑已发送的消息
+func (b *Bot) Edit(channel string, messageID int, message string, opts *SendOptions) error {
 	chat, err := b.bot.ChatByUsername(channel)
 	if err != nil {
 		return err
 	}
 
-	_, err = b.bot.Send(chat, message, &tele.SendOptions{
-		ParseMode: tele.ModeMarkdown,
-	})
+	stored := tele.StoredMessage{MessageID: strconv.Itoa(messageID), ChatID: chat.ID}
+	_, err = b.bot.Edit(stored, message, buildSendOptions(opts))
+	// 内容没有变化时 Telegram 会返回错误，视为成功
+	if errors.Is(err, tele.ErrMessageNotModified) {
+		return nil
+	}
 	return err
 }
+
+// Reply 回复已发送的消息，返回新消息 ID
+func (b *Bot) Reply(channel string, messageID int, message string, opts *SendOptions) (int, error) {
+	chat, err := b.bot.ChatByUsername(channel)
+	if err != nil {
+		return 0, err
+	}
+
+	sendOpts := buildSendOptions(opts)
+	sendOpts.ReplyTo = &tele.Message{ID: messageID, Chat: chat}
+	sendOpts.AllowWithoutReply = true
+
+	msg, err := b.bot.Send(chat, message, sendOpts)
+	if err != nil {
+		return 0, err
+	}
+	return msg.ID, nil
+}
+
+// Delete 删除已发送的消息
+func (b *Bot) Delete(channel string, messageID int) error {
+	chat, err := b.bot.ChatByUsername(channel)
+	if err != nil {
+		return err
+	}
+
+	return b.bot.Delete(tele.StoredMessage{MessageID: strconv.Itoa(messageID), ChatID: chat.ID})
+}
+
+// 转换为 telebot 的发送选项
+func buildSendOptions(opts *SendOptions) *tele.SendOptions {
+	sendOpts := &tele.SendOptions{
+		ParseMode: tele.ModeMarkdown,
+	}
+	if opts == nil {
+		return sendOpts
+	}
+
+	switch opts.ParseMode {
+	case "":
+	case ParseModeNone:
+		sendOpts.ParseMode = tele.ModeDefault
+	default:
+		sendOpts.ParseMode = tele.ParseMode(opts.ParseMode)
+	}
+	sendOpts.DisableNotification = opts.DisableNotification
+	sendOpts.DisableWebPagePreview = opts.DisableWebPagePreview
+	sendOpts.Protected = opts.ProtectContent
+
+	if len(opts.Buttons) > 0 {
+		row := make([]tele.InlineButton, 0, len(opts.Buttons))
+		for _, button := range opts.Buttons {
+			row = append(row, tele.InlineButton{Text: button.Text, URL: button.URL})
+		}
+		sendOpts.ReplyMarkup = &tele.ReplyMarkup{
+			InlineKeyboard: [][]tele.InlineButton{row},
+		}
+	}
+	return sendOpts
+}
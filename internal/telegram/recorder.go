@@ -0,0 +1,79 @@
+package telegram
+
+//dry-run 模式使用的消息记录器
+//与 Bot 提供相同的方法，不调用 Telegram API，将每次操作以 JSON Lines 格式写入输出
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// 记录的操作类型
+const (
+	ActionSend   = "send"
+	ActionEdit   = "edit"
+	ActionReply  = "reply"
+	ActionDelete = "delete"
+)
+
+// Record 一次消息操作的记录
+type Record struct {
+	Time      time.Time    `json:"time"`
+	Action    string       `json:"action"`
+	Channel   string       `json:"channel"`
+	MessageID int          `json:"message_id"`         // send/reply 时为分配的虚拟消息 ID
+	ReplyTo   int          `json:"reply_to,omitempty"` // reply 回复的消息 ID
+	Text      string       `json:"text,omitempty"`
+	Options   *SendOptions `json:"options,omitempty"`
+}
+
+// Recorder 记录将要发送的消息
+type Recorder struct {
+	mu     sync.Mutex
+	out    *json.Encoder
+	nextID int
+}
+
+// NewRecorder 创建消息记录器，记录写入 out
+func NewRecorder(out io.Writer) *Recorder {
+	return &Recorder{out: json.NewEncoder(out)}
+}
+
+// Send 记录发送的消息，返回虚拟消息 ID
+func (r *Recorder) Send(channel string, message string, opts *SendOptions) (int, error) {
+	return r.record(Record{Action: ActionSend, Channel: channel, Text: message, Options: opts})
+}
+
+// Edit 记录编辑的消息
+func (r *Recorder) Edit(channel string, messageID int, message string, opts *SendOptions) error {
+	_, err := r.record(Record{Action: ActionEdit, Channel: channel, MessageID: messageID, Text: message, Options: opts})
+	return err
+}
+
+// Reply 记录回复的消息，返回虚拟消息 ID
+func (r *Recorder) Reply(channel string, messageID int, message string, opts *SendOptions) (int, error) {
+	return r.record(Record{Action: ActionReply, Channel: channel, ReplyTo: messageID, Text: message, Options: opts})
+}
+
+// Delete 记录删除的消息
+func (r *Recorder) Delete(channel string, messageID int) error {
+	_, err := r.record(Record{Action: ActionDelete, Channel: channel, MessageID: messageID})
+	return err
+}
+
+func (r *Recorder) record(rec Record) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec.Action == ActionSend || rec.Action == ActionReply {
+		r.nextID++
+		rec.MessageID = r.nextID
+	}
+	rec.Time = time.Now()
+	if err := r.out.Encode(rec); err != nil {
+		return 0, err
+	}
+	return rec.MessageID, nil
+}